@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/metalagman/tgbotspec/internal/diff"
+
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Compare two Bot API versions (HTML snapshots, IR dumps or specs, - for stdin)",
+		Long: "Compare two Bot API versions and list added, removed and changed methods, parameters, " +
+			"types and fields, classified as breaking or additive. Each input may be a documentation " +
+			"HTML snapshot, an IR dump (--emit-ir) or a generated OpenAPI spec.",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			write, err := diffWriter(format)
+			if err != nil {
+				return err
+			}
+
+			from, err := loadAPI(cmd.Context(), args[0], cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			to, err := loadAPI(cmd.Context(), args[1], cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			return write(diff.Compare(from, to), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, markdown or json)")
+
+	return cmd
+}
+
+func loadAPI(ctx context.Context, path string, stdin io.Reader) (*diff.API, error) {
+	data, err := readInput(path, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	api, err := diff.Load(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return api, nil
+}
+
+// diffWriter returns the report writer for the diff --format flag.
+func diffWriter(format string) (func(*diff.Report, io.Writer) error, error) {
+	switch format {
+	case "text":
+		return (*diff.Report).WriteText, nil
+	case "markdown", "md":
+		return (*diff.Report).WriteMarkdown, nil
+	case "json":
+		return (*diff.Report).WriteJSON, nil
+	default:
+		return nil, fmt.Errorf("unknown diff format %q (want text, markdown or json)", format)
+	}
+}
@@ -1,9 +1,21 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
+	"github.com/metalagman/tgbotspec/internal/fetcher"
+	"github.com/metalagman/tgbotspec/internal/openapi"
+	"github.com/metalagman/tgbotspec/internal/overrides"
+	"github.com/metalagman/tgbotspec/internal/parser"
 	"github.com/metalagman/tgbotspec/internal/scraper"
 
 	"github.com/spf13/cobra"
@@ -17,7 +29,18 @@ var (
 func newRootCmd() *cobra.Command {
 	var (
 		outputPath      string
+		source          = sourceFlags{http: fetcher.DefaultHTTPOptions()}
 		mergeUnionTypes bool
+		diagnostics     string
+		strict          bool
+		validate        bool
+		specVersion     string
+		format          string
+		templatePath    string
+		irPath          string
+		emitIR          bool
+		overridesPath   string
+		overlayPaths    []string
 	)
 
 	cmd := &cobra.Command{
@@ -25,29 +48,90 @@ func newRootCmd() *cobra.Command {
 		Short:        "Generate an OpenAPI spec for the Telegram Bot API",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			writeDiagnostics, err := diagnosticsWriter(diagnostics)
+			if err != nil {
+				return err
+			}
+
+			openAPIVersion, err := openAPIVersion(specVersion)
+			if err != nil {
+				return err
+			}
+
+			outputFormat, err := outputFormat(format, outputPath)
+			if err != nil {
+				return err
+			}
+
 			output := cmd.OutOrStdout()
 
 			if outputPath != "" {
-				file, err := os.Create(outputPath)
-				if err != nil {
-					return fmt.Errorf("create output file: %w", err)
+				file, createErr := createOutput(outputPath)
+				if createErr != nil {
+					return createErr
 				}
 
 				defer func() {
-					if closeErr := file.Close(); closeErr != nil && err == nil {
-						err = fmt.Errorf("close output file: %w", closeErr)
-					}
+					err = file.finish(err)
 				}()
 
 				output = file
 			}
 
+			report := &parser.Report{}
 			opts := scraper.Options{
+				EmitIR:          emitIR,
+				Source:          source.source(cmd.InOrStdin()),
 				MergeUnionTypes: mergeUnionTypes,
+				Diagnostics:     report,
+				Strict:          strict,
+				Validate:        validate,
+				OpenAPIVersion:  openAPIVersion,
+				Format:          outputFormat,
+				Template:        templatePath,
+			}
+
+			if irPath != "" {
+				data, err := readInput(irPath, cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+
+				opts.IR = bytes.NewReader(data)
 			}
 
-			if err := runScraper(output, opts); err != nil {
-				return fmt.Errorf("run scraper: %w", err)
+			if overridesPath != "" {
+				opts.Overrides, err = overrides.Load(overridesPath)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, path := range overlayPaths {
+				overlay, err := openapi.LoadOverlay(path)
+				if err != nil {
+					return err
+				}
+
+				opts.Overlays = append(opts.Overlays, overlay)
+			}
+
+			runErr := runScraper(cmd.Context(), output, opts)
+
+			switch {
+			case writeDiagnostics != nil:
+				if err := writeDiagnostics(report, cmd.ErrOrStderr()); err != nil {
+					return err
+				}
+			case errors.Is(runErr, scraper.ErrStrict):
+				// Show what failed the strict run even without --diagnostics.
+				if err := report.Only(parser.SeverityError).WriteText(cmd.ErrOrStderr()); err != nil {
+					return err
+				}
+			}
+
+			if runErr != nil {
+				return fmt.Errorf("run scraper: %w", runErr)
 			}
 
 			return nil
@@ -55,18 +139,190 @@ func newRootCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "Write output to file instead of stdout")
+	cmd.Flags().StringVarP(&source.inputPath, "input", "i", "",
+		"Read the Bot API HTML from a local file instead of fetching it (use - for stdin)")
+	cmd.Flags().StringVar(&source.url, "url", "",
+		"Fetch the Bot API HTML from this URL instead of "+fetcher.DefaultURL+" (not cached)")
+	cmd.Flags().StringVar(&source.cacheDir, "cache-dir", fetcher.DefaultCacheDir(),
+		"Directory for cached pages and version snapshots (env "+fetcher.CacheDirEnv+")")
+	cmd.Flags().StringVar(&source.snapshot, "snapshot", "",
+		"Use the cached snapshot of this Bot API version (e.g. 7.2) instead of fetching")
+	cmd.Flags().DurationVar(&source.http.Timeout, "timeout", source.http.Timeout,
+		"Timeout of a single HTTP request attempt (0 disables it)")
+	cmd.Flags().IntVar(&source.http.Retries, "retries", source.http.Retries,
+		"Retries for transient HTTP failures (5xx, 429, connection errors)")
 	cmd.Flags().BoolVar(&mergeUnionTypes, "merge-union-types", false,
 		"Merge union types (made only from refs) into one type")
+	cmd.Flags().StringVar(&diagnostics, "diagnostics", "",
+		"Print parse diagnostics to stderr in this format (text or json)")
+	cmd.Flags().BoolVar(&strict, "strict", false,
+		"Exit non-zero on any parse error or unresolved type reference")
+	cmd.Flags().BoolVar(&validate, "validate", true,
+		"Validate the generated spec with kin-openapi and fail instead of writing an invalid one")
+	cmd.Flags().StringVar(&specVersion, "openapi-version", "3.0",
+		"OpenAPI version of the generated spec (3.0 or 3.1)")
+	cmd.Flags().StringVar(&format, "format", "",
+		"Output format (yaml or json); inferred from the --output extension, yaml by default")
+	cmd.Flags().StringVar(&templatePath, "template", "",
+		"Render with this Go template file instead of the document model ("+openapi.TemplateBuiltin+
+			" for the embedded one)")
+	cmd.Flags().BoolVar(&emitIR, "emit-ir", false,
+		"Write the parsed documentation as JSON intermediate representation instead of the spec")
+	cmd.Flags().StringVar(&irPath, "ir", "",
+		"Read the parsed documentation from an intermediate representation file instead of HTML (use - for stdin)")
+	cmd.Flags().StringVar(&overridesPath, "overrides", "",
+		"Apply the corrections of this YAML overrides file to the parsed documentation")
+	cmd.Flags().StringArrayVar(&overlayPaths, "overlay", nil,
+		"Apply this OpenAPI Overlay 1.0 file to the generated spec (repeatable, applied in order)")
+	cmd.MarkFlagsMutuallyExclusive("input", "url", "snapshot", "ir")
+
+	cmd.AddCommand(newValidateCmd(), newDiffCmd())
 
 	return cmd
 }
 
+// outputFile is a temporary file next to the --output path that replaces it
+// only when the run succeeds, so a failed run leaves an existing spec intact.
+type outputFile struct {
+	*os.File
+	path string
+}
+
+func createOutput(path string) (*outputFile, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("create output file: %w", err)
+	}
+
+	return &outputFile{File: file, path: path}, nil
+}
+
+// finish moves the written file over the output path when runErr is nil and
+// removes it otherwise. It returns runErr or the error of moving the file.
+func (f *outputFile) finish(runErr error) error {
+	err := runErr
+	if closeErr := f.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("close output file: %w", closeErr)
+	}
+
+	if err == nil {
+		err = f.commit()
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+
+	return err
+}
+
+// commit gives the temporary file the mode of the file it replaces, or the
+// usual mode of a new file, and renames it over the output path.
+func (f *outputFile) commit() error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(f.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := os.Chmod(f.Name(), mode); err != nil {
+		return fmt.Errorf("write output file: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		return fmt.Errorf("write output file: %w", err)
+	}
+
+	return nil
+}
+
+// sourceFlags holds the CLI flags selecting where the documentation comes from.
+type sourceFlags struct {
+	inputPath string
+	url       string
+	cacheDir  string
+	snapshot  string
+	http      fetcher.HTTPOptions
+}
+
+// source picks the documentation source from the CLI flags, defaulting to the
+// official docs cached in the configured cache directory.
+func (f sourceFlags) source(stdin io.Reader) fetcher.Source {
+	switch {
+	case f.inputPath == "-":
+		return fetcher.NewReaderSource(stdin)
+	case f.inputPath != "":
+		return fetcher.NewFileSource(f.inputPath)
+	case f.url != "":
+		src := fetcher.NewURLSource(f.url)
+		src.HTTP = f.http
+
+		return src
+	}
+
+	cache := fetcher.NewCache(f.cacheDir)
+	if f.snapshot != "" {
+		return fetcher.NewSnapshotSource(cache, fetcher.DefaultURL, f.snapshot)
+	}
+
+	src := fetcher.NewCachedSource(fetcher.DefaultURL, cache)
+	src.HTTP = f.http
+
+	return src
+}
+
+// diagnosticsWriter returns the report writer for the --diagnostics format,
+// or nil when diagnostics are not requested.
+func diagnosticsWriter(format string) (func(*parser.Report, io.Writer) error, error) {
+	switch format {
+	case "":
+		return nil, nil
+	case "text":
+		return (*parser.Report).WriteText, nil
+	case "json":
+		return (*parser.Report).WriteJSON, nil
+	default:
+		return nil, fmt.Errorf("unknown diagnostics format %q (want text or json)", format)
+	}
+}
+
+// openAPIVersion maps the --openapi-version flag to the emitted version.
+func openAPIVersion(flag string) (string, error) {
+	switch flag {
+	case "3.0", openapi.OpenAPIVersion30:
+		return openapi.OpenAPIVersion30, nil
+	case "3.1", openapi.OpenAPIVersion31:
+		return openapi.OpenAPIVersion31, nil
+	default:
+		return "", fmt.Errorf("unsupported OpenAPI version %q (want 3.0 or 3.1)", flag)
+	}
+}
+
+// outputFormat returns the --format value, inferring it from the extension of
+// the output file when the flag is not set.
+func outputFormat(flag, outputPath string) (string, error) {
+	switch flag {
+	case openapi.FormatYAML, openapi.FormatJSON:
+		return flag, nil
+	case "":
+		if strings.EqualFold(filepath.Ext(outputPath), ".json") {
+			return openapi.FormatJSON, nil
+		}
+
+		return openapi.FormatYAML, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (want yaml or json)", flag)
+	}
+}
+
 func execute() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	rootCmd := newRootCmd()
 	rootCmd.SetOut(os.Stdout)
 	rootCmd.SetErr(os.Stderr)
 
-	return rootCmd.Execute()
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func main() {
@@ -2,17 +2,23 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/metalagman/tgbotspec/internal/fetcher"
+	"github.com/metalagman/tgbotspec/internal/openapi"
 	"github.com/metalagman/tgbotspec/internal/scraper"
 )
 
 func TestNewRootCmd(t *testing.T) {
 	originalRun := runScraper
-	runScraper = func(w io.Writer, opts scraper.Options) error {
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
 		if w == nil {
 			t.Fatal("expected writer to be non-nil")
 		}
@@ -47,7 +53,7 @@ func TestNewRootCmd(t *testing.T) {
 
 func TestNewRootCmdOutputFlag(t *testing.T) {
 	originalRun := runScraper
-	runScraper = func(w io.Writer, opts scraper.Options) error {
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
 		if w == nil {
 			t.Fatal("expected writer to be non-nil")
 		}
@@ -92,6 +98,166 @@ func TestNewRootCmdOutputFlag(t *testing.T) {
 	}
 }
 
+func TestNewRootCmdOutputKeptOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "keep.yaml")
+	inputPath := filepath.Join(dir, "api.html")
+	templatePath := filepath.Join(dir, "invalid.gotmpl")
+
+	for path, data := range map[string]string{
+		outputPath:   "existing spec",
+		inputPath:    "<html><body><h1>Telegram Bot API</h1></body></html>",
+		templatePath: "openapi: 3.0.0\ninfo: {}\npaths: {}\n",
+	} {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"-o", outputPath, "--input", inputPath, "--template", templatePath},
+		{"-o", outputPath, "--input", filepath.Join(dir, "missing.html")},
+	} {
+		cmd := newRootCmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+
+		contents, err := os.ReadFile(outputPath)
+		if err != nil || string(contents) != "existing spec" {
+			t.Fatalf("expected the existing output to survive %v, got %q (err %v)", args, contents, err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected temporary output files to be removed, got %v", entries)
+	}
+}
+
+// fixtureHTML are Bot API pages from the scraper tests, which the default
+// flags, including validation, must turn into a spec. They are keyed by a
+// line the spec must contain.
+var fixtureHTML = map[string]string{
+	"  /sendMediaGroup:\n": `<html><body>
+	<p><strong>Bot API 7.0</strong></p>
+	<a data-target="#InputMedia">InputMedia</a>
+	<a data-target="#InputMediaPhoto">InputMediaPhoto</a>
+	<a data-target="#InputMediaVideo">InputMediaVideo</a>
+	<a data-target="#Message">Message</a>
+	<a data-target="#sendMediaGroup">sendMediaGroup</a>
+	<h3>Available types</h3>
+	<h4><a class="anchor" name="InputMedia"></a>InputMedia</h4>
+	<p>This object represents the content of a media message to be sent.</p>
+	<ul><li>InputMediaPhoto</li><li>InputMediaVideo</li></ul>
+	<h4><a class="anchor" name="InputMediaPhoto"></a>InputMediaPhoto</h4>
+	<table><tbody><tr><td>type</td><td>String</td><td>Type of the result</td></tr></tbody></table>
+	<h4><a class="anchor" name="InputMediaVideo"></a>InputMediaVideo</h4>
+	<table><tbody><tr><td>type</td><td>String</td><td>Type of the result</td></tr></tbody></table>
+	<h4><a class="anchor" name="Message"></a>Message</h4>
+	<table><tbody><tr><td>message_id</td><td>Integer</td><td>Unique message identifier</td></tr></tbody></table>
+	<h3>Available methods</h3>
+	<h4><a class="anchor" name="sendMediaGroup"></a>sendMediaGroup</h4>
+	<p>Use this method to send a group of photos, videos, documents or audios as an album.
+		On success, an array of Messages that were sent is returned.</p>
+	<table><tbody>
+		<tr><td>chat_id</td><td>Integer</td><td>Unique identifier for the target chat</td></tr>
+		<tr><td>media</td><td>Array of InputMediaPhoto or InputMediaVideo</td><td>Photos and videos to be sent</td></tr>
+	</tbody></table>
+</body></html>`,
+	"    Reply:\n": replyHTML,
+}
+
+// replyHTML has a plural reference the resolver fixes; danglingHTML adds one
+// to a type the page does not define.
+const (
+	replyHTML = `<html><body>
+	<a data-target="#Reply">Reply</a>
+	<a data-target="#Message">Message</a>
+	<h3>Available types</h3>
+	<h4><a class="anchor" name="Reply"></a>Reply</h4>
+	<table><tbody>
+		<tr><td>messages</td><td>Array of Messages</td><td>Replied messages</td></tr>
+	</tbody></table>
+	<h4><a class="anchor" name="Message"></a>Message</h4>
+	<table><tbody><tr><td>text</td><td>String</td><td>Text</td></tr></tbody></table>
+</body></html>`
+	danglingRow = `<tr><td>origin</td><td>MessageOrigin</td><td>Origin of the reply</td></tr>
+	</tbody></table>`
+)
+
+func TestNewRootCmdDefaultFlagsFixtures(t *testing.T) {
+	for want, html := range fixtureHTML {
+		dir := t.TempDir()
+		inputPath := filepath.Join(dir, "api.html")
+		outputPath := filepath.Join(dir, "openapi.yaml")
+
+		if err := os.WriteFile(inputPath, []byte(html), 0o600); err != nil {
+			t.Fatalf("write input: %v", err)
+		}
+
+		cmd := newRootCmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"-i", inputPath, "-o", outputPath})
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("expected the default flags to succeed for %q, got %v", want, err)
+		}
+
+		contents, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("read output: %v", err)
+		}
+
+		if !strings.Contains(string(contents), want) {
+			t.Fatalf("expected %q in the spec, got %q", want, contents)
+		}
+	}
+}
+
+func TestNewRootCmdValidatesByDefault(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "api.html")
+	outputPath := filepath.Join(dir, "openapi.yaml")
+
+	dangling := strings.Replace(replyHTML, "</tbody></table>", danglingRow, 1)
+	if err := os.WriteFile(inputPath, []byte(dangling), 0o600); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	cmd := newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"-i", inputPath, "-o", outputPath})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "MessageOrigin") {
+		t.Fatalf("expected validation to name the dangling reference, got %v", err)
+	}
+
+	if _, statErr := os.Stat(outputPath); !errors.Is(statErr, os.ErrNotExist) {
+		t.Fatalf("expected no spec to be written, got %v", statErr)
+	}
+
+	cmd = newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"-i", inputPath, "-o", outputPath, "--validate=false"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected --validate=false to write the spec, got %v", err)
+	}
+}
+
 func TestNewRootCmdError(t *testing.T) {
 	cmd := newRootCmd()
 	// Use an invalid path to trigger os.Create error
@@ -109,7 +275,7 @@ func TestMainSuccess(t *testing.T) {
 	originalExit := exit
 	originalArgs := os.Args
 
-	runScraper = func(w io.Writer, opts scraper.Options) error {
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
 		return nil
 	}
 
@@ -138,7 +304,7 @@ func TestMainExitOnError(t *testing.T) {
 	originalExit := exit
 	originalArgs := os.Args
 
-	runScraper = func(w io.Writer, opts scraper.Options) error {
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
 		return errors.New("boom")
 	}
 
@@ -170,3 +336,642 @@ func TestMainExitOnError(t *testing.T) {
 		t.Fatalf("expected exit code 1, got %d", exitCode)
 	}
 }
+
+func TestNewRootCmdSourceFlags(t *testing.T) {
+	originalRun := runScraper
+
+	var got fetcher.Source
+
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
+		got = opts.Source
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		runScraper = originalRun
+	})
+
+	inputPath := t.TempDir() + string(os.PathSeparator) + "api.html"
+	cacheDir := t.TempDir()
+
+	tests := []struct {
+		name  string
+		args  []string
+		check func(src fetcher.Source) bool
+	}{
+		{
+			name: "default",
+			args: []string{"--cache-dir", cacheDir},
+			check: func(src fetcher.Source) bool {
+				cs, ok := src.(*fetcher.CachedSource)
+
+				return ok && cs.URL == fetcher.DefaultURL && cs.Cache.Dir == cacheDir
+			},
+		},
+		{
+			name: "http options",
+			args: []string{"--url", "https://example.com", "--timeout", "5s", "--retries", "7"},
+			check: func(src fetcher.Source) bool {
+				us, ok := src.(*fetcher.URLSource)
+
+				return ok && us.HTTP.Timeout == 5*time.Second && us.HTTP.Retries == 7
+			},
+		},
+		{
+			name: "snapshot",
+			args: []string{"--cache-dir", cacheDir, "--snapshot", "7.2"},
+			check: func(src fetcher.Source) bool {
+				ss, ok := src.(*fetcher.SnapshotSource)
+
+				return ok && ss.Version == "7.2" && ss.Cache.Dir == cacheDir
+			},
+		},
+		{
+			name: "file",
+			args: []string{"--input", inputPath},
+			check: func(src fetcher.Source) bool {
+				fs, ok := src.(*fetcher.FileSource)
+
+				return ok && fs.Path == inputPath
+			},
+		},
+		{
+			name: "stdin",
+			args: []string{"-i", "-"},
+			check: func(src fetcher.Source) bool {
+				_, ok := src.(*fetcher.ReaderSource)
+
+				return ok
+			},
+		},
+		{
+			name: "url",
+			args: []string{"--url", "https://mirror.example.com/api"},
+			check: func(src fetcher.Source) bool {
+				us, ok := src.(*fetcher.URLSource)
+
+				return ok && us.URL == "https://mirror.example.com/api"
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+
+			cmd := newRootCmd()
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.SetArgs(tc.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+
+			if !tc.check(got) {
+				t.Fatalf("unexpected source %#v", got)
+			}
+		})
+	}
+}
+
+func TestNewRootCmdSourceFlagsExclusive(t *testing.T) {
+	cmd := newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--input", "api.html", "--url", "https://example.com"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error when both --input and --url are set")
+	}
+}
+
+func TestNewRootCmdDiagnostics(t *testing.T) {
+	originalRun := runScraper
+
+	var strict bool
+
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
+		strict = opts.Strict
+		opts.Diagnostics.Errorf("sendmessage", "Use this method", "method return type not parsed")
+
+		if opts.Strict {
+			return scraper.ErrStrict
+		}
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		runScraper = originalRun
+	})
+
+	stderr := &bytes.Buffer{}
+
+	cmd := newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{"--diagnostics", "text"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	want := "error: sendmessage: method return type not parsed: \"Use this method\"\n"
+	if stderr.String() != want {
+		t.Fatalf("unexpected text diagnostics %q", stderr.String())
+	}
+
+	stderr.Reset()
+
+	cmd = newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{"--diagnostics", "json", "--strict"})
+
+	err := cmd.Execute()
+	if !strict || !errors.Is(err, scraper.ErrStrict) {
+		t.Fatalf("expected strict failure, got %v", err)
+	}
+
+	if !bytes.Contains(stderr.Bytes(), []byte(`"severity": "error"`)) {
+		t.Fatalf("expected JSON diagnostics even on failure, got %q", stderr.String())
+	}
+}
+
+func TestNewRootCmdStrictWithoutDiagnostics(t *testing.T) {
+	originalRun := runScraper
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
+		opts.Diagnostics.Errorf("sendMediaGroup", "Array", "unresolved type reference Array")
+		opts.Diagnostics.Warnf("Reply.messages", "Messages", "resolved type reference Messages to Message")
+
+		return scraper.ErrStrict
+	}
+
+	t.Cleanup(func() {
+		runScraper = originalRun
+	})
+
+	stderr := &bytes.Buffer{}
+
+	cmd := newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{"--strict"})
+
+	if err := cmd.Execute(); !errors.Is(err, scraper.ErrStrict) {
+		t.Fatalf("expected strict failure, got %v", err)
+	}
+
+	want := "error: sendMediaGroup: unresolved type reference Array: \"Array\"\n"
+	if !strings.HasPrefix(stderr.String(), want) {
+		t.Fatalf("expected only the errors on stderr, got %q", stderr.String())
+	}
+
+	if strings.Contains(stderr.String(), "warning") {
+		t.Fatalf("expected warnings to be left out, got %q", stderr.String())
+	}
+}
+
+func TestNewRootCmdDiagnosticsUnknownFormat(t *testing.T) {
+	cmd := newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--diagnostics", "xml"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for unknown diagnostics format")
+	}
+}
+
+func TestNewRootCmdValidateFlag(t *testing.T) {
+	originalRun := runScraper
+
+	var validate bool
+
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
+		validate = opts.Validate
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		runScraper = originalRun
+	})
+
+	for _, tc := range []struct {
+		args []string
+		want bool
+	}{
+		{args: []string{}, want: true},
+		{args: []string{"--validate=false"}, want: false},
+	} {
+		cmd := newRootCmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(tc.args)
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute returned error: %v", err)
+		}
+
+		if validate != tc.want {
+			t.Fatalf("expected Validate=%v for %v", tc.want, tc.args)
+		}
+	}
+}
+
+func TestNewRootCmdOpenAPIVersion(t *testing.T) {
+	originalRun := runScraper
+
+	var version string
+
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
+		version = opts.OpenAPIVersion
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		runScraper = originalRun
+	})
+
+	for args, want := range map[string]string{
+		"3.0":   openapi.OpenAPIVersion30,
+		"3.1":   openapi.OpenAPIVersion31,
+		"3.1.0": openapi.OpenAPIVersion31,
+	} {
+		cmd := newRootCmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"--openapi-version", args})
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute returned error: %v", err)
+		}
+
+		if version != want {
+			t.Fatalf("expected %s for %s, got %q", want, args, version)
+		}
+	}
+
+	cmd := newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--openapi-version", "2.0"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for an unsupported OpenAPI version")
+	}
+}
+
+func TestNewRootCmdFormat(t *testing.T) {
+	originalRun := runScraper
+
+	var format string
+
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
+		format = opts.Format
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		runScraper = originalRun
+	})
+
+	dir := t.TempDir() + string(os.PathSeparator)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{}, want: openapi.FormatYAML},
+		{args: []string{"--format", "json"}, want: openapi.FormatJSON},
+		{args: []string{"-o", dir + "spec.json"}, want: openapi.FormatJSON},
+		{args: []string{"-o", dir + "spec.yml"}, want: openapi.FormatYAML},
+		{args: []string{"-o", dir + "spec.json", "--format", "yaml"}, want: openapi.FormatYAML},
+	}
+
+	for _, tc := range tests {
+		cmd := newRootCmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(tc.args)
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute returned error: %v", err)
+		}
+
+		if format != tc.want {
+			t.Fatalf("expected %s for %v, got %q", tc.want, tc.args, format)
+		}
+	}
+
+	cmd := newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--format", "xml"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for an unknown output format")
+	}
+}
+
+func TestValidateCmd(t *testing.T) {
+	dir := t.TempDir()
+	valid := dir + string(os.PathSeparator) + "valid.yaml"
+	invalid := dir + string(os.PathSeparator) + "invalid.yaml"
+
+	spec := "openapi: 3.0.0\ninfo:\n  title: T\n  version: \"1\"\npaths: {}\n"
+	if err := os.WriteFile(valid, []byte(spec), 0o600); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+
+	broken := spec + "components:\n  schemas:\n    User:\n      $ref: '#/components/schemas/Missing'\n"
+	if err := os.WriteFile(invalid, []byte(broken), 0o600); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+
+	cmd := newRootCmd()
+	cmd.SetOut(out)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"validate", valid})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected valid spec, got %v", err)
+	}
+
+	if out.String() != valid+": valid\n" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+
+	cmd = newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetIn(bytes.NewBufferString(broken))
+	cmd.SetArgs([]string{"validate", "-"})
+
+	var validationErr *openapi.ValidationError
+	if err := cmd.Execute(); !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error for stdin, got %v", err)
+	}
+
+	cmd = newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"validate", invalid})
+
+	if err := cmd.Execute(); !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error for %s, got %v", invalid, err)
+	}
+
+	cmd = newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"validate", dir + string(os.PathSeparator) + "missing.yaml"})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for a missing file")
+	}
+}
+
+func TestNewRootCmdTemplate(t *testing.T) {
+	originalRun := runScraper
+
+	var template string
+
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
+		template = opts.Template
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		runScraper = originalRun
+	})
+
+	cmd := newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--template", "custom.gotmpl"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if template != "custom.gotmpl" {
+		t.Fatalf("expected template to be passed through, got %q", template)
+	}
+}
+
+func TestNewRootCmdIR(t *testing.T) {
+	originalRun := runScraper
+
+	var got scraper.Options
+
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
+		got = opts
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		runScraper = originalRun
+	})
+
+	path := filepath.Join(t.TempDir(), "api.json")
+	if err := os.WriteFile(path, []byte(`{"version": 1}`), 0o600); err != nil {
+		t.Fatalf("write IR: %v", err)
+	}
+
+	cmd := newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--ir", path, "--emit-ir"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if got.IR == nil || !got.EmitIR {
+		t.Fatalf("expected IR input and output to be set, got %+v", got)
+	}
+
+	data, err := io.ReadAll(got.IR)
+	if err != nil || string(data) != `{"version": 1}` {
+		t.Fatalf("unexpected IR input %q (err %v)", data, err)
+	}
+
+	for _, args := range [][]string{
+		{"--ir", filepath.Join(t.TempDir(), "missing.json")},
+		{"--ir", path, "--input", "api.html"},
+	} {
+		cmd := newRootCmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestNewRootCmdOverrides(t *testing.T) {
+	originalRun := runScraper
+
+	var got scraper.Options
+
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
+		got = opts
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		runScraper = originalRun
+	})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "overrides.yaml")
+
+	if err := os.WriteFile(path, []byte("methods:\n  - name: getMe\n    return: User\n"), 0o600); err != nil {
+		t.Fatalf("write overrides: %v", err)
+	}
+
+	cmd := newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--overrides", path})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if got.Overrides == nil || len(got.Overrides.Methods) != 1 {
+		t.Fatalf("expected overrides to be loaded, got %+v", got.Overrides)
+	}
+
+	cmd = newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--overrides", filepath.Join(dir, "missing.yaml")})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for a missing overrides file")
+	}
+}
+
+func TestNewRootCmdOverlay(t *testing.T) {
+	originalRun := runScraper
+
+	var got scraper.Options
+
+	runScraper = func(ctx context.Context, w io.Writer, opts scraper.Options) error {
+		got = opts
+
+		return nil
+	}
+
+	t.Cleanup(func() {
+		runScraper = originalRun
+	})
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yaml")
+	second := filepath.Join(dir, "second.yaml")
+	data := []byte("overlay: 1.0.0\ninfo: {title: T, version: 1.0.0}\nactions:\n  - target: $.info\n    update: {x-a: 1}\n")
+
+	for _, path := range []string{first, second} {
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("write overlay: %v", err)
+		}
+	}
+
+	cmd := newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--overlay", first, "--overlay", second})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if len(got.Overlays) != 2 || got.Overlays[0].Name != first || got.Overlays[1].Name != second {
+		t.Fatalf("expected overlays in flag order, got %+v", got.Overlays)
+	}
+
+	cmd = newRootCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--overlay", filepath.Join(dir, "missing.yaml")})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for a missing overlay file")
+	}
+}
+
+func TestDiffCmd(t *testing.T) {
+	dir := t.TempDir()
+	oldSpec := filepath.Join(dir, "old.yaml")
+	newSpec := filepath.Join(dir, "new.json")
+
+	spec := "openapi: 3.0.0\ninfo:\n  title: T\n  version: \"7.0\"\npaths:\n" +
+		"  /getMe:\n    post:\n      responses: {}\n  /logOut:\n    post:\n      responses: {}\n"
+	if err := os.WriteFile(oldSpec, []byte(spec), 0o600); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+
+	updated := `{"openapi": "3.0.0", "info": {"title": "T", "version": "7.1"}, ` +
+		`"paths": {"/getMe": {"post": {"responses": {}}}, "/close": {"post": {"responses": {}}}}}`
+	if err := os.WriteFile(newSpec, []byte(updated), 0o600); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "text", want: "Bot API 7.0 -> 7.1: 2 change(s), 1 breaking\n" +
+			"breaking: method logOut removed\nadditive: method close added\n"},
+		{format: "markdown", want: "# Bot API 7.0 → 7.1\n\n## Breaking changes\n\n- Method `logOut` removed\n\n" +
+			"## Additive changes\n\n- Method `close` added\n"},
+		{format: "json", want: `"path": "logOut"`},
+	}
+
+	for _, tc := range tests {
+		out := &bytes.Buffer{}
+
+		cmd := newRootCmd()
+		cmd.SetOut(out)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"diff", "--format", tc.format, oldSpec, newSpec})
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("diff --format %s returned error: %v", tc.format, err)
+		}
+
+		if !strings.Contains(out.String(), tc.want) {
+			t.Errorf("diff --format %s: expected %q in\n%s", tc.format, tc.want, out.String())
+		}
+	}
+
+	for _, args := range [][]string{
+		{"diff", "--format", "xml", oldSpec, newSpec},
+		{"diff", oldSpec, filepath.Join(dir, "missing.yaml")},
+		{"diff", oldSpec},
+	} {
+		cmd := newRootCmd()
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
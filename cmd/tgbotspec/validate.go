@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/metalagman/tgbotspec/internal/openapi"
+
+	"github.com/spf13/cobra"
+)
+
+func newValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "validate <file>",
+		Short:        "Validate an existing OpenAPI spec (YAML or JSON, - for stdin)",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := readInput(args[0], cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			if err := openapi.Validate(cmd.Context(), data); err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s: valid\n", args[0])
+
+			return err
+		},
+	}
+}
+
+// readInput reads a file named by an argument or flag, where - means stdin.
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read stdin: %w", err)
+		}
+
+		return data, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read input: %w", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,372 @@
+// Package diff compares two versions of the Bot API and classifies the
+// differences as breaking or additive for API clients.
+package diff
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/metalagman/tgbotspec/internal/fetcher"
+	"github.com/metalagman/tgbotspec/internal/ir"
+	"github.com/metalagman/tgbotspec/internal/openapi"
+	"github.com/metalagman/tgbotspec/internal/parser"
+	"github.com/metalagman/tgbotspec/internal/scraper"
+)
+
+// ErrUnknownInput is returned by Load for data that is neither documentation
+// HTML, an IR dump nor an OpenAPI spec.
+var ErrUnknownInput = errors.New("input is not Bot API HTML, IR or an OpenAPI spec")
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// API is the surface of a Bot API version that Compare looks at. Types are
+// spelled as in the documentation ("Array of Message", "Integer or String")
+// whatever the input was, so HTML, IR and specs can be compared with each
+// other.
+type API struct {
+	Version string
+	Methods map[string]Method
+	Types   map[string]Type
+}
+
+// Method is the signature of an API method.
+type Method struct {
+	Params map[string]Field
+	// Return is the type of the result, empty when not documented.
+	Return string
+}
+
+// Type is an object or, when OneOf is set, an abstract type.
+type Type struct {
+	Fields map[string]Field
+	OneOf  []string
+}
+
+// Field is a field of a type or a parameter of a method.
+type Field struct {
+	Type     string
+	Required bool
+}
+
+// Load builds the API from documentation HTML, an IR dump or an OpenAPI spec
+// in YAML or JSON, detecting which one data is.
+func Load(ctx context.Context, data []byte) (*API, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		doc, err := scraper.Parse(ctx, scraper.Options{Source: fetcher.NewReaderSource(bytes.NewReader(data))})
+		if err != nil {
+			return nil, err
+		}
+
+		return FromIR(doc), nil
+	}
+
+	var probe struct {
+		OpenAPI string      `yaml:"openapi"`
+		Version interface{} `yaml:"version"`
+	}
+
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnknownInput, err)
+	}
+
+	switch {
+	case probe.OpenAPI != "":
+		return FromSpec(data)
+	case probe.Version != nil:
+		doc, err := ir.Read(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		return FromIR(doc), nil
+	default:
+		return nil, ErrUnknownInput
+	}
+}
+
+// FromIR builds the API from parsed documentation.
+func FromIR(doc *ir.Document) *API {
+	api := &API{
+		Version: doc.APIVersion,
+		Methods: make(map[string]Method, len(doc.Methods)),
+		Types:   make(map[string]Type, len(doc.Types)),
+	}
+
+	for _, m := range doc.MethodDefs() {
+		method := Method{Params: make(map[string]Field, len(m.Params))}
+		if m.Return != nil {
+			method.Return = typeName(m.Return.ToTypeSpec())
+		}
+
+		for _, p := range m.Params {
+			method.Params[p.Name] = Field{Type: rawTypeName(p.TypeRef), Required: p.Required}
+		}
+
+		api.Methods[m.Name] = method
+	}
+
+	for _, t := range doc.TypeDefs() {
+		// InputFile is a pseudo-type that never becomes a schema.
+		if t.Name == "InputFile" {
+			continue
+		}
+
+		typ := Type{Fields: make(map[string]Field, len(t.Fields)), OneOf: t.OneOf}
+		for _, f := range t.Fields {
+			typ.Fields[f.Name] = Field{Type: rawTypeName(f.TypeRef), Required: f.Required}
+		}
+
+		api.Types[t.Name] = typ
+	}
+
+	return api
+}
+
+// specDocument is the part of an OpenAPI document FromSpec reads.
+type specDocument struct {
+	Info struct {
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Paths map[string]struct {
+		Post *struct {
+			RequestBody struct {
+				Content map[string]struct {
+					Schema *schema `yaml:"schema"`
+				} `yaml:"content"`
+			} `yaml:"requestBody"`
+			Responses map[string]struct {
+				Content map[string]struct {
+					Schema *schema `yaml:"schema"`
+				} `yaml:"content"`
+			} `yaml:"responses"`
+		} `yaml:"post"`
+	} `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*schema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+// schema is the part of an OpenAPI 3.0 or 3.1 schema that tells its type.
+type schema struct {
+	// Type is a name, or a list of names in OpenAPI 3.1.
+	Type       interface{}        `yaml:"type"`
+	Format     string             `yaml:"format"`
+	Ref        string             `yaml:"$ref"`
+	Items      *schema            `yaml:"items"`
+	OneOf      []schema           `yaml:"oneOf"`
+	AnyOf      []schema           `yaml:"anyOf"`
+	AllOf      []schema           `yaml:"allOf"`
+	Properties map[string]*schema `yaml:"properties"`
+	Required   []string           `yaml:"required"`
+	Enum       []interface{}      `yaml:"enum"`
+	Const      interface{}        `yaml:"const"`
+	Default    interface{}        `yaml:"default"`
+}
+
+// FromSpec builds the API from an OpenAPI spec generated by this tool, in
+// YAML or JSON. The template schemas OkResponse and ErrorResponse are left out.
+func FromSpec(data []byte) (*API, error) {
+	var doc specDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+
+	api := &API{
+		Version: doc.Info.Version,
+		Methods: make(map[string]Method, len(doc.Paths)),
+		Types:   make(map[string]Type, len(doc.Components.Schemas)),
+	}
+
+	for path, item := range doc.Paths {
+		if item.Post == nil {
+			continue
+		}
+
+		method := Method{Params: map[string]Field{}}
+
+		if body := item.Post.RequestBody.Content["application/json"].Schema; body != nil {
+			method.Params = fields(body)
+		}
+
+		// The multipart body adds the parameters that only take files and
+		// spells "InputFile or String" parameters as InputFile.
+		if body := item.Post.RequestBody.Content["multipart/form-data"].Schema; body != nil {
+			for name, f := range fields(body) {
+				if jsonField, ok := method.Params[name]; ok && jsonField.Type != f.Type {
+					f.Type += " or " + jsonField.Type
+				}
+
+				method.Params[name] = f
+			}
+		}
+
+		if ok := item.Post.Responses["200"].Content["application/json"].Schema; ok != nil {
+			for _, part := range ok.AllOf {
+				if result, found := part.Properties["result"]; found {
+					method.Return = typeName(result.typeSpec())
+				}
+			}
+		}
+
+		api.Methods[strings.TrimPrefix(path, "/")] = method
+	}
+
+	for name, s := range doc.Components.Schemas {
+		if name == "OkResponse" || name == "ErrorResponse" || s == nil {
+			continue
+		}
+
+		typ := Type{Fields: fields(s)}
+
+		if len(s.Properties) == 0 {
+			for _, variant := range s.OneOf {
+				if variant.Ref != "" {
+					typ.OneOf = append(typ.OneOf, strings.TrimPrefix(variant.Ref, schemaRefPrefix))
+				}
+			}
+		}
+
+		api.Types[name] = typ
+	}
+
+	return api, nil
+}
+
+func fields(s *schema) map[string]Field {
+	res := make(map[string]Field, len(s.Properties))
+
+	for name, prop := range s.Properties {
+		if prop == nil {
+			prop = &schema{}
+		}
+
+		res[name] = Field{Type: typeName(prop.typeSpec())}
+	}
+
+	for _, name := range s.Required {
+		if f, ok := res[name]; ok {
+			f.Required = true
+			res[name] = f
+		}
+	}
+
+	return res
+}
+
+// typeSpec converts the schema to the renderer's model, folding the OpenAPI
+// 3.1 spellings of nullable types and constants back to 3.0 ones.
+func (s *schema) typeSpec() *openapi.TypeSpec {
+	if s == nil {
+		return &openapi.TypeSpec{}
+	}
+
+	spec := &openapi.TypeSpec{
+		Format:  s.Format,
+		Enum:    s.Enum,
+		Default: s.Default,
+	}
+
+	if s.Items != nil {
+		spec.Items = s.Items.typeSpec()
+	}
+
+	if s.Const != nil {
+		spec.Enum = []interface{}{s.Const}
+	}
+
+	switch t := s.Type.(type) {
+	case string:
+		spec.Type = t
+	case []interface{}:
+		for _, v := range t {
+			if name, ok := v.(string); ok && name != "null" {
+				spec.Type = name
+			}
+		}
+	}
+
+	if s.Ref != "" {
+		spec.Ref = &openapi.TypeRef{Name: strings.TrimPrefix(s.Ref, schemaRefPrefix)}
+	}
+
+	for _, list := range []struct {
+		from []schema
+		to   *[]openapi.TypeSpec
+	}{{s.OneOf, &spec.OneOf}, {s.AnyOf, &spec.AnyOf}, {s.AllOf, &spec.AllOf}} {
+		for i := range list.from {
+			if list.from[i].Type == "null" {
+				continue
+			}
+
+			*list.to = append(*list.to, *list.from[i].typeSpec())
+		}
+	}
+
+	return spec
+}
+
+func rawTypeName(ref *parser.TypeRef) string {
+	if ref == nil {
+		return ""
+	}
+
+	return typeName(ref.ToTypeSpec())
+}
+
+// typeName spells a schema the way the documentation does.
+func typeName(spec *openapi.TypeSpec) string {
+	switch {
+	case spec == nil:
+		return ""
+	case spec.Ref != nil:
+		return spec.Ref.Name
+	case len(spec.AllOf) == 1:
+		return typeName(&spec.AllOf[0])
+	case len(spec.OneOf) > 0:
+		return unionName(spec.OneOf)
+	case len(spec.AnyOf) > 0:
+		return unionName(spec.AnyOf)
+	}
+
+	switch spec.Type {
+	case "array":
+		return "Array of " + typeName(spec.Items)
+	case "string":
+		if spec.Format == "binary" {
+			return "InputFile"
+		}
+
+		return "String"
+	case "integer":
+		return "Integer"
+	case "number":
+		return "Float"
+	case "boolean":
+		// A default is not a type: "Defaults to True" parameters and the True
+		// pseudo-type, which is rendered as a boolean defaulting to true, both
+		// read as Boolean. Only a constant true is True.
+		if len(spec.Enum) == 1 && spec.Enum[0] == true {
+			return "True"
+		}
+
+		return "Boolean"
+	case "object":
+		return "Object"
+	}
+
+	return spec.Type
+}
+
+func unionName(variants []openapi.TypeSpec) string {
+	names := make([]string, 0, len(variants))
+	for i := range variants {
+		names = append(names, typeName(&variants[i]))
+	}
+
+	return strings.Join(names, " or ")
+}
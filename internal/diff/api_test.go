@@ -0,0 +1,179 @@
+package diff_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/tgbotspec/internal/diff"
+	"github.com/metalagman/tgbotspec/internal/fetcher"
+	"github.com/metalagman/tgbotspec/internal/ir"
+	"github.com/metalagman/tgbotspec/internal/openapi"
+	"github.com/metalagman/tgbotspec/internal/scraper"
+)
+
+const botAPIHTML = `<html><head><title>Telegram Bot API</title></head><body>
+<p><strong>Bot API 7.1</strong></p>
+<h3><a class="anchor" name="available-types"></a>Available types</h3>
+<ul>
+	<li><a href="#user">User</a></li>
+	<li><a href="#message">Message</a></li>
+	<li><a href="#chatmember">ChatMember</a></li>
+	<li><a href="#chatmemberowner">ChatMemberOwner</a></li>
+	<li><a href="#responseparameters">ResponseParameters</a></li>
+</ul>
+<h4><a class="anchor" name="user"></a>User</h4>
+<p>This object represents a Telegram user or bot.</p>
+<table><tbody>
+	<tr><td>id</td><td>Integer</td><td>Unique identifier for this user or bot.</td></tr>
+	<tr><td>is_bot</td><td>Boolean</td><td>True, if this user is a bot</td></tr>
+	<tr><td>tags</td><td>Array of String</td><td><em>Optional</em>. Tags.</td></tr>
+</tbody></table>
+<h4><a class="anchor" name="message"></a>Message</h4>
+<p>This object represents a message.</p>
+<table><tbody>
+	<tr><td>message_id</td><td>Integer</td><td>Unique message identifier</td></tr>
+	<tr><td>from</td><td><a href="#user">User</a></td><td><em>Optional</em>. Sender</td></tr>
+</tbody></table>
+<h4><a class="anchor" name="chatmember"></a>ChatMember</h4>
+<p>This object contains information about one member of a chat. Currently, the following 1 type of chat members are supported:</p>
+<ul><li><a href="#chatmemberowner">ChatMemberOwner</a></li></ul>
+<h4><a class="anchor" name="chatmemberowner"></a>ChatMemberOwner</h4>
+<p>Represents a chat member that owns the chat.</p>
+<table><tbody>
+	<tr><td>user</td><td><a href="#user">User</a></td><td>Information about the user</td></tr>
+</tbody></table>
+<h4><a class="anchor" name="responseparameters"></a>ResponseParameters</h4>
+<p>Describes why a request was unsuccessful.</p>
+<table><tbody>
+	<tr><td>retry_after</td><td>Integer</td><td><em>Optional</em>. Seconds left to wait</td></tr>
+</tbody></table>
+<h3><a class="anchor" name="available-methods"></a>Available methods</h3>
+<ul>
+	<li><a href="#getme">getMe</a></li>
+	<li><a href="#sendmessage">sendMessage</a></li>
+	<li><a href="#logout">logOut</a></li>
+</ul>
+<h4><a class="anchor" name="getme"></a>getMe</h4>
+<p>Returns basic information about the bot in form of a <a href="#user">User</a> object.</p>
+<h4><a class="anchor" name="sendmessage"></a>sendMessage</h4>
+<p>Use this method to send text messages. On success, the sent <a href="#message">Message</a> is returned.</p>
+<table><tbody>
+	<tr><td>chat_id</td><td>Integer or String</td><td>Yes</td><td>Unique identifier for the target chat</td></tr>
+	<tr><td>text</td><td>String</td><td>Yes</td><td>Text of the message to be sent</td></tr>
+	<tr><td>photo</td><td>InputFile or String</td><td>Optional</td><td>Photo to send</td></tr>
+	<tr><td>protect_content</td><td>Boolean</td><td>Optional</td><td>Protects the message. Defaults to True.</td></tr>
+</tbody></table>
+<h4><a class="anchor" name="logout"></a>logOut</h4>
+<p>Use this method to log out from the cloud Bot API server. Returns <em>True</em> on success.</p>
+</body></html>`
+
+func loadAll(t *testing.T, html string) map[string]*diff.API {
+	t.Helper()
+
+	res := map[string]*diff.API{}
+
+	api, err := diff.Load(t.Context(), []byte(html))
+	if err != nil {
+		t.Fatalf("load HTML: %v", err)
+	}
+
+	res["html"] = api
+
+	for name, opts := range map[string]scraper.Options{
+		"ir":        {EmitIR: true},
+		"spec":      {},
+		"spec-json": {Format: openapi.FormatJSON},
+		"spec-3.1":  {OpenAPIVersion: openapi.OpenAPIVersion31},
+	} {
+		opts.Source = fetcher.NewReaderSource(strings.NewReader(html))
+
+		var buf bytes.Buffer
+		if err := scraper.Run(t.Context(), &buf, opts); err != nil {
+			t.Fatalf("render %s: %v", name, err)
+		}
+
+		api, err := diff.Load(t.Context(), buf.Bytes())
+		if err != nil {
+			t.Fatalf("load %s: %v", name, err)
+		}
+
+		res[name] = api
+	}
+
+	return res
+}
+
+func TestLoadInputsAgree(t *testing.T) {
+	apis := loadAll(t, botAPIHTML)
+
+	html := apis["html"]
+	if got := html.Methods["sendMessage"].Params["chat_id"]; got != (diff.Field{Type: "Integer or String", Required: true}) {
+		t.Fatalf("unexpected chat_id parameter %+v", got)
+	}
+
+	if got := html.Methods["sendMessage"].Params["protect_content"]; got != (diff.Field{Type: "Boolean"}) {
+		t.Fatalf("a default must not change the type, got %+v", got)
+	}
+
+	if got := html.Types["ChatMember"].OneOf; !reflect.DeepEqual(got, []string{"ChatMemberOwner"}) {
+		t.Fatalf("unexpected ChatMember variants %v", got)
+	}
+
+	for name, api := range apis {
+		if api.Version != "7.1" {
+			t.Errorf("%s: unexpected version %q", name, api.Version)
+		}
+
+		if r := diff.Compare(html, api); len(r.Changes) != 0 {
+			var buf bytes.Buffer
+			if err := r.WriteText(&buf); err != nil {
+				t.Fatalf("write report: %v", err)
+			}
+
+			t.Errorf("%s input differs from HTML:\n%s", name, buf.String())
+		}
+	}
+}
+
+func TestFromSpecTrue(t *testing.T) {
+	spec := `openapi: 3.1.0
+info: {title: T, version: "7.1"}
+components:
+  schemas:
+    Flags:
+      type: object
+      properties:
+        always: {type: boolean, enum: [true]}
+        constant: {type: boolean, const: true}
+        defaulted: {type: boolean, default: true}
+`
+
+	api, err := diff.FromSpec([]byte(spec))
+	if err != nil {
+		t.Fatalf("FromSpec returned error: %v", err)
+	}
+
+	want := map[string]diff.Field{
+		"always":    {Type: "True"},
+		"constant":  {Type: "True"},
+		"defaulted": {Type: "Boolean"},
+	}
+	if got := api.Types["Flags"].Fields; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected fields %+v", got)
+	}
+}
+
+func TestLoadUnknownInput(t *testing.T) {
+	for _, input := range []string{"just text", "[1, 2]", "{\"name\": \"x\"}"} {
+		if _, err := diff.Load(t.Context(), []byte(input)); !errors.Is(err, diff.ErrUnknownInput) {
+			t.Errorf("expected ErrUnknownInput for %q, got %v", input, err)
+		}
+	}
+
+	if _, err := diff.Load(t.Context(), []byte(`{"version": 42}`)); !errors.Is(err, ir.ErrUnsupportedVersion) {
+		t.Errorf("expected ErrUnsupportedVersion, got %v", err)
+	}
+}
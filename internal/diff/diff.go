@@ -0,0 +1,304 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Element names the part of the API a Change is about.
+type Element string
+
+const (
+	ElementMethod    Element = "method"
+	ElementParameter Element = "parameter"
+	ElementReturn    Element = "return type"
+	ElementType      Element = "type"
+	ElementField     Element = "field"
+	ElementVariant   Element = "variant"
+)
+
+// Kind tells how an element changed.
+type Kind string
+
+const (
+	KindAdded   Kind = "added"
+	KindRemoved Kind = "removed"
+	KindChanged Kind = "changed"
+)
+
+// Change is a single difference between two API versions.
+type Change struct {
+	// Breaking marks changes that can break existing clients: removals, new
+	// required parameters, parameter types that no longer accept a value they
+	// used to, field and return type changes and fields that are no longer
+	// guaranteed. Everything else is additive.
+	Breaking bool    `json:"breaking"`
+	Kind     Kind    `json:"kind"`
+	Element  Element `json:"element"`
+	// Path locates the element: "sendMessage", "sendMessage.text", "Message"
+	// or "Message.text".
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
+
+// Report lists the changes between two API versions, breaking ones first.
+type Report struct {
+	OldVersion string   `json:"old_version"`
+	NewVersion string   `json:"new_version"`
+	Changes    []Change `json:"changes"`
+}
+
+// Breaking returns the number of breaking changes.
+func (r *Report) Breaking() int {
+	n := 0
+
+	for _, c := range r.Changes {
+		if c.Breaking {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Compare reports what changed between the from and to versions.
+func Compare(from, to *API) *Report {
+	r := &Report{OldVersion: from.Version, NewVersion: to.Version, Changes: []Change{}}
+
+	for _, name := range names(from.Methods, to.Methods) {
+		before, inOld := from.Methods[name]
+		after, inNew := to.Methods[name]
+
+		switch {
+		case !inNew:
+			r.add(true, KindRemoved, ElementMethod, name, "", "")
+		case !inOld:
+			r.add(false, KindAdded, ElementMethod, name, "", "")
+		default:
+			r.compareFields(ElementParameter, name, before.Params, after.Params)
+
+			if before.Return != after.Return {
+				r.add(true, KindChanged, ElementReturn, name, before.Return, after.Return)
+			}
+		}
+	}
+
+	for _, name := range names(from.Types, to.Types) {
+		before, inOld := from.Types[name]
+		after, inNew := to.Types[name]
+
+		switch {
+		case !inNew:
+			r.add(true, KindRemoved, ElementType, name, "", "")
+		case !inOld:
+			r.add(false, KindAdded, ElementType, name, "", "")
+		default:
+			r.compareFields(ElementField, name, before.Fields, after.Fields)
+			r.compareVariants(name, before.OneOf, after.OneOf)
+		}
+	}
+
+	sort.SliceStable(r.Changes, func(i, j int) bool {
+		return r.Changes[i].Breaking && !r.Changes[j].Breaking
+	})
+
+	return r
+}
+
+// compareFields compares the parameters of a method or the fields of a type.
+// Parameters are written by clients and fields are read by them, so a
+// parameter becoming required breaks callers while a field becoming optional
+// breaks readers. Likewise a parameter type may widen (Integer to Integer or
+// String) without breaking anyone, but any change to a field type does.
+func (r *Report) compareFields(element Element, owner string, from, to map[string]Field) {
+	for _, name := range names(from, to) {
+		before, inOld := from[name]
+		after, inNew := to[name]
+		path := owner + "." + name
+
+		switch {
+		case !inNew:
+			r.add(true, KindRemoved, element, path, before.Type, "")
+		case !inOld:
+			r.add(element == ElementParameter && after.Required, KindAdded, element, path, "", after.Type)
+
+			added := &r.Changes[len(r.Changes)-1]
+			added.Message += fmt.Sprintf(" (%s, %s)", orNone(after.Type), requiredness(after.Required))
+		default:
+			if before.Type != after.Type {
+				breaking := element != ElementParameter || !widens(before.Type, after.Type)
+				r.add(breaking, KindChanged, element, path, before.Type, after.Type)
+			}
+
+			if before.Required != after.Required {
+				breaking := after.Required == (element == ElementParameter)
+				r.add(breaking, KindChanged, element, path, requiredness(before.Required), requiredness(after.Required))
+			}
+		}
+	}
+}
+
+// widens reports whether type to accepts every value of type from: each
+// alternative of from, e.g. Integer in "Integer or String", is one of to.
+func widens(from, to string) bool {
+	alternatives := strings.Split(to, " or ")
+
+	for _, alt := range strings.Split(from, " or ") {
+		if !slices.Contains(alternatives, alt) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *Report) compareVariants(owner string, from, to []string) {
+	for _, name := range from {
+		if !slices.Contains(to, name) {
+			r.add(true, KindRemoved, ElementVariant, owner+"."+name, "", "")
+		}
+	}
+
+	for _, name := range to {
+		if !slices.Contains(from, name) {
+			r.add(false, KindAdded, ElementVariant, owner+"."+name, "", "")
+		}
+	}
+}
+
+func (r *Report) add(breaking bool, kind Kind, element Element, path, before, after string) {
+	c := Change{Breaking: breaking, Kind: kind, Element: element, Path: path, Old: before, New: after}
+
+	switch kind {
+	case KindAdded:
+		c.Message = fmt.Sprintf("%s %s added", element, path)
+	case KindRemoved:
+		c.Message = fmt.Sprintf("%s %s removed", element, path)
+	case KindChanged:
+		c.Message = fmt.Sprintf("%s %s changed from %s to %s", element, path, orNone(before), orNone(after))
+	}
+
+	r.Changes = append(r.Changes, c)
+}
+
+// WriteText writes one line per change, e.g.
+//
+//	breaking: parameter sendMessage.chat_id changed from Integer or String to Integer
+func (r *Report) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Bot API %s -> %s: %d change(s), %d breaking\n",
+		orNone(r.OldVersion), orNone(r.NewVersion), len(r.Changes), r.Breaking()); err != nil {
+		return err
+	}
+
+	for _, c := range r.Changes {
+		severity := "additive"
+		if c.Breaking {
+			severity = "breaking"
+		}
+
+		if _, err := fmt.Fprintf(w, "%s: %s\n", severity, c.Message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteMarkdown writes the changes as release notes grouped into breaking and
+// additive sections.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Bot API %s → %s\n", orNone(r.OldVersion), orNone(r.NewVersion))
+
+	if len(r.Changes) == 0 {
+		b.WriteString("\nNo changes.\n")
+	}
+
+	for _, section := range []struct {
+		title    string
+		breaking bool
+	}{{"Breaking changes", true}, {"Additive changes", false}} {
+		first := true
+
+		for _, c := range r.Changes {
+			if c.Breaking != section.breaking {
+				continue
+			}
+
+			if first {
+				fmt.Fprintf(&b, "\n## %s\n\n", section.title)
+
+				first = false
+			}
+
+			fmt.Fprintf(&b, "- %s\n", markdownMessage(c))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(r)
+}
+
+func markdownMessage(c Change) string {
+	subject := fmt.Sprintf("%s `%s`", strings.ToUpper(string(c.Element[:1]))+string(c.Element[1:]), c.Path)
+
+	switch c.Kind {
+	case KindAdded:
+		return subject + strings.TrimPrefix(c.Message, fmt.Sprintf("%s %s", c.Element, c.Path))
+	case KindRemoved:
+		return subject + " removed"
+	default:
+		return fmt.Sprintf("%s changed from `%s` to `%s`", subject, orNone(c.Old), orNone(c.New))
+	}
+}
+
+func requiredness(required bool) string {
+	if required {
+		return "required"
+	}
+
+	return "optional"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+
+	return s
+}
+
+// names returns the keys of both maps, sorted.
+func names[V any](from, to map[string]V) []string {
+	res := make([]string, 0, len(from)+len(to))
+
+	for name := range from {
+		res = append(res, name)
+	}
+
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			res = append(res, name)
+		}
+	}
+
+	sort.Strings(res)
+
+	return res
+}
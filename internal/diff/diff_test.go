@@ -0,0 +1,197 @@
+package diff_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/tgbotspec/internal/diff"
+)
+
+func testAPIs() (*diff.API, *diff.API) {
+	from := &diff.API{
+		Version: "7.0",
+		Methods: map[string]diff.Method{
+			"getMe":  {Return: "User"},
+			"logOut": {Return: "True"},
+			"sendPhoto": {Return: "Message", Params: map[string]diff.Field{
+				"chat_id": {Type: "Integer", Required: true},
+				"caption": {Type: "String"},
+				"photo":   {Type: "InputFile or String", Required: true},
+			}},
+		},
+		Types: map[string]diff.Type{
+			"User": {Fields: map[string]diff.Field{
+				"id":       {Type: "Integer", Required: true},
+				"username": {Type: "String", Required: true},
+			}},
+			"ChatMember": {OneOf: []string{"ChatMemberOwner", "ChatMemberLeft"}},
+			"Poll":       {},
+		},
+	}
+
+	to := &diff.API{
+		Version: "7.1",
+		Methods: map[string]diff.Method{
+			"getMe":    {Return: "User"},
+			"sendDice": {Return: "Message"},
+			"sendPhoto": {Return: "Array of Message", Params: map[string]diff.Field{
+				"chat_id":          {Type: "Integer or String", Required: true},
+				"caption":          {Type: "String", Required: true},
+				"photo":            {Type: "String", Required: true},
+				"has_spoiler":      {Type: "Boolean"},
+				"business_conn_id": {Type: "String", Required: true},
+			}},
+		},
+		Types: map[string]diff.Type{
+			"User": {Fields: map[string]diff.Field{
+				"id":         {Type: "Integer or String", Required: true},
+				"username":   {Type: "String"},
+				"is_premium": {Type: "True"},
+			}},
+			"ChatMember": {OneOf: []string{"ChatMemberOwner", "ChatMemberBanned"}},
+			"Story":      {},
+		},
+	}
+
+	return from, to
+}
+
+func TestCompare(t *testing.T) {
+	r := diff.Compare(testAPIs())
+
+	want := []string{
+		"breaking: method logOut removed",
+		"breaking: parameter sendPhoto.business_conn_id added (String, required)",
+		"breaking: parameter sendPhoto.caption changed from optional to required",
+		"breaking: parameter sendPhoto.photo changed from InputFile or String to String",
+		"breaking: return type sendPhoto changed from Message to Array of Message",
+		"breaking: variant ChatMember.ChatMemberLeft removed",
+		"breaking: type Poll removed",
+		"breaking: field User.id changed from Integer to Integer or String",
+		"breaking: field User.username changed from required to optional",
+		"additive: method sendDice added",
+		"additive: parameter sendPhoto.chat_id changed from Integer to Integer or String",
+		"additive: parameter sendPhoto.has_spoiler added (Boolean, optional)",
+		"additive: variant ChatMember.ChatMemberBanned added",
+		"additive: type Story added",
+		"additive: field User.is_premium added (True, optional)",
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText returned error: %v", err)
+	}
+
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if got[0] != "Bot API 7.0 -> 7.1: 15 change(s), 9 breaking" {
+		t.Errorf("unexpected summary %q", got[0])
+	}
+
+	if strings.Join(got[1:], "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected changes:\n%s\nwant\n%s", strings.Join(got[1:], "\n"), strings.Join(want, "\n"))
+	}
+
+	if r.Breaking() != 9 {
+		t.Errorf("expected 9 breaking changes, got %d", r.Breaking())
+	}
+
+	from, _ := testAPIs()
+	if same := diff.Compare(from, from); len(same.Changes) != 0 {
+		t.Errorf("expected no changes between equal APIs, got %+v", same.Changes)
+	}
+}
+
+func TestCompareTypeChanges(t *testing.T) {
+	api := func(param, field, result string) *diff.API {
+		return &diff.API{
+			Methods: map[string]diff.Method{"getChat": {Return: result, Params: map[string]diff.Field{
+				"chat_id": {Type: param, Required: true},
+			}}},
+			Types: map[string]diff.Type{"Chat": {Fields: map[string]diff.Field{
+				"id": {Type: field, Required: true},
+			}}},
+		}
+	}
+
+	narrow := api("Integer", "Integer", "Chat")
+	wide := api("Integer or String", "Integer or String", "Chat or Boolean")
+
+	cases := []struct {
+		from, to *diff.API
+		want     map[string]bool
+	}{
+		{narrow, wide, map[string]bool{"getChat.chat_id": false, "Chat.id": true, "getChat": true}},
+		{wide, narrow, map[string]bool{"getChat.chat_id": true, "Chat.id": true, "getChat": true}},
+		{api("Array of Integer", "String", "Chat"), api("Array of String", "String", "Chat"),
+			map[string]bool{"getChat.chat_id": true}},
+	}
+
+	for i, tc := range cases {
+		got := map[string]bool{}
+		for _, c := range diff.Compare(tc.from, tc.to).Changes {
+			got[c.Path] = c.Breaking
+		}
+
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("case %d: breaking by path %v, want %v", i, got, tc.want)
+		}
+	}
+}
+
+func TestReportMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := diff.Compare(testAPIs()).WriteMarkdown(&buf); err != nil {
+		t.Fatalf("WriteMarkdown returned error: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"# Bot API 7.0 → 7.1\n\n## Breaking changes\n\n- Method `logOut` removed\n",
+		"- Parameter `sendPhoto.photo` changed from `InputFile or String` to `String`\n",
+		"\n## Additive changes\n\n- Method `sendDice` added\n",
+		"- Parameter `sendPhoto.chat_id` changed from `Integer` to `Integer or String`\n",
+		"- Field `User.is_premium` added (True, optional)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+
+	from, _ := testAPIs()
+	if err := diff.Compare(from, from).WriteMarkdown(&buf); err != nil {
+		t.Fatalf("WriteMarkdown returned error: %v", err)
+	}
+
+	if buf.String() != "# Bot API 7.0 → 7.0\n\nNo changes.\n" {
+		t.Errorf("unexpected empty report %q", buf.String())
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := diff.Compare(testAPIs()).WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON returned error: %v", err)
+	}
+
+	var got diff.Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal report: %v", err)
+	}
+
+	first := got.Changes[0]
+	if got.OldVersion != "7.0" || got.NewVersion != "7.1" || !first.Breaking ||
+		first.Kind != diff.KindRemoved || first.Element != diff.ElementMethod || first.Path != "logOut" {
+		t.Fatalf("unexpected report %+v", got)
+	}
+
+	for _, c := range got.Changes {
+		if c.Path == "sendPhoto.chat_id" && (c.Old != "Integer" || c.New != "Integer or String") {
+			t.Errorf("unexpected type change %+v", c)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package fetcher
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheDirEnv overrides the default cache directory when set.
+const CacheDirEnv = "TGBOTSPEC_CACHE_DIR"
+
+const (
+	cacheDirPerm     = 0o755
+	cacheFilePerm    = 0o644
+	cachePageFile    = "page.html"
+	cacheMetaFile    = "meta.json"
+	cacheVersionsDir = "versions"
+	snapshotExt      = ".html"
+)
+
+// ErrSnapshotNotFound indicates that no cached snapshot exists for the
+// requested Bot API version.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// CacheMeta describes a cached page: where it came from, the HTTP validators
+// needed to revalidate it and the Bot API version it documents.
+type CacheMeta struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Version      string    `json:"version,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// CacheEntry is a cached page together with its metadata.
+type CacheEntry struct {
+	Meta CacheMeta
+	HTML []byte
+}
+
+// Cache stores fetched pages in a directory, one subdirectory per URL. Each
+// subdirectory holds the latest page, its metadata and historical snapshots
+// keyed by Bot API version:
+//
+//	<dir>/<url key>/page.html
+//	<dir>/<url key>/meta.json
+//	<dir>/<url key>/versions/<version>.html
+type Cache struct {
+	Dir string
+}
+
+// NewCache builds a Cache rooted at dir.
+func NewCache(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+// DefaultCacheDir returns the cache directory used when none is configured:
+// $TGBOTSPEC_CACHE_DIR if set, otherwise tgbotspec under the user cache dir.
+func DefaultCacheDir() string {
+	if dir := strings.TrimSpace(os.Getenv(CacheDirEnv)); dir != "" {
+		return dir
+	}
+
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+
+	return filepath.Join(base, "tgbotspec")
+}
+
+// Load returns the latest cached entry for url. A missing entry is reported
+// with an error matching os.ErrNotExist.
+func (c *Cache) Load(url string) (*CacheEntry, error) {
+	dir := c.urlDir(url)
+
+	metaData, err := os.ReadFile(filepath.Join(dir, cacheMetaFile))
+	if err != nil {
+		return nil, fmt.Errorf("read cache meta: %w", err)
+	}
+
+	var meta CacheMeta
+	if err := json.Unmarshal(metaData, &meta); err != nil {
+		return nil, fmt.Errorf("decode cache meta: %w", err)
+	}
+
+	html, err := os.ReadFile(filepath.Join(dir, cachePageFile))
+	if err != nil {
+		return nil, fmt.Errorf("read cache page: %w", err)
+	}
+
+	return &CacheEntry{Meta: meta, HTML: html}, nil
+}
+
+// Store writes the entry as the latest page for its URL and, when the version
+// is known, keeps a snapshot of it under that version.
+func (c *Cache) Store(entry *CacheEntry) error {
+	dir := c.urlDir(entry.Meta.URL)
+	if err := os.MkdirAll(filepath.Join(dir, cacheVersionsDir), cacheDirPerm); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(dir, cachePageFile), entry.HTML); err != nil {
+		return fmt.Errorf("write cache page: %w", err)
+	}
+
+	if isSnapshotVersion(entry.Meta.Version) {
+		path := filepath.Join(dir, cacheVersionsDir, entry.Meta.Version+snapshotExt)
+		if err := writeFileAtomic(path, entry.HTML); err != nil {
+			return fmt.Errorf("write cache snapshot: %w", err)
+		}
+	}
+
+	return c.StoreMeta(entry.Meta)
+}
+
+// StoreMeta rewrites only the metadata of a cached page, e.g. after a
+// successful revalidation.
+func (c *Cache) StoreMeta(meta CacheMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cache meta: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(c.urlDir(meta.URL), cacheMetaFile), data); err != nil {
+		return fmt.Errorf("write cache meta: %w", err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so an interrupted run never leaves a truncated cache file.
+func writeFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(file.Name(), cacheFilePerm)
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(file.Name())
+	}
+
+	return err
+}
+
+// Snapshot returns the cached page of url for the given Bot API version.
+func (c *Cache) Snapshot(url, version string) ([]byte, error) {
+	if !isSnapshotVersion(version) {
+		return nil, fmt.Errorf("bot api %q: %w", version, ErrSnapshotNotFound)
+	}
+
+	path := filepath.Join(c.urlDir(url), cacheVersionsDir, version+snapshotExt)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("bot api %s: %w", version, ErrSnapshotNotFound)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read cache snapshot: %w", err)
+	}
+
+	return data, nil
+}
+
+// Versions lists the Bot API versions with a cached snapshot for url, oldest
+// first.
+func (c *Cache) Versions(url string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(c.urlDir(url), cacheVersionsDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("list cache snapshots: %w", err)
+	}
+
+	versions := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), snapshotExt) {
+			continue
+		}
+
+		versions = append(versions, strings.TrimSuffix(e.Name(), snapshotExt))
+	}
+
+	slices.SortFunc(versions, compareVersions)
+
+	return versions, nil
+}
+
+// compareVersions orders versions by their dot or dash separated components,
+// numerically where both components are numbers, so 7.9 sorts before 7.10.
+func compareVersions(a, b string) int {
+	split := func(r rune) bool { return r == '.' || r == '-' }
+	as, bs := strings.FieldsFunc(a, split), strings.FieldsFunc(b, split)
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+
+		c := cmp.Compare(as[i], bs[i])
+		if aErr == nil && bErr == nil {
+			c = cmp.Compare(an, bn)
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Or(cmp.Compare(len(as), len(bs)), cmp.Compare(a, b))
+}
+
+// isSnapshotVersion reports whether version is safe to use as a file name.
+func isSnapshotVersion(version string) bool {
+	if version == "" || strings.Trim(version, ".") == "" {
+		return false
+	}
+
+	for _, r := range version {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && r != '.' && r != '-' {
+			return false
+		}
+	}
+
+	return !strings.Contains(version, "..")
+}
+
+// urlDir maps a URL onto a readable directory name inside the cache.
+func (c *Cache) urlDir(url string) string {
+	key := strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	key = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+
+	return filepath.Join(c.Dir, key)
+}
+
+// SnapshotSource serves a historical page from the cache, allowing specs for
+// older Bot API versions to be regenerated offline.
+type SnapshotSource struct {
+	Cache   *Cache
+	URL     string
+	Version string
+}
+
+// NewSnapshotSource builds a SnapshotSource for the given cached version.
+func NewSnapshotSource(cache *Cache, url, version string) *SnapshotSource {
+	return &SnapshotSource{Cache: cache, URL: url, Version: version}
+}
+
+// HTML returns the cached snapshot. When it is missing, the error lists the
+// versions that are cached.
+func (s *SnapshotSource) HTML(_ context.Context) ([]byte, error) {
+	data, err := s.Cache.Snapshot(s.URL, s.Version)
+	if !errors.Is(err, ErrSnapshotNotFound) {
+		return data, err
+	}
+
+	versions, listErr := s.Cache.Versions(s.URL)
+	if listErr != nil || len(versions) == 0 {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("%w (cached: %s)", err, strings.Join(versions, ", "))
+}
@@ -0,0 +1,46 @@
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnexpectedStatus is matched by StatusError.
+var ErrUnexpectedStatus = errors.New("unexpected HTTP status")
+
+// ErrInvalidDocument is matched by InvalidDocumentError.
+var ErrInvalidDocument = errors.New("not a Telegram Bot API reference")
+
+// StatusError reports a non-2xx response.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("fetch %s: %s: %s", e.URL, ErrUnexpectedStatus, e.Status)
+}
+
+func (e *StatusError) Unwrap() error {
+	return ErrUnexpectedStatus
+}
+
+// InvalidDocumentError reports a page that was fetched successfully but does
+// not look like the Bot API reference, e.g. an error or captive-portal page.
+type InvalidDocumentError struct {
+	URL    string
+	Reason string
+}
+
+func (e *InvalidDocumentError) Error() string {
+	if e.URL == "" {
+		return fmt.Sprintf("%s: %s", ErrInvalidDocument, e.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", e.URL, ErrInvalidDocument, e.Reason)
+}
+
+func (e *InvalidDocumentError) Unwrap() error {
+	return ErrInvalidDocument
+}
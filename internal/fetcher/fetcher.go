@@ -2,30 +2,49 @@ package fetcher
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/go-resty/resty/v2"
 )
 
+// DefaultURL is the canonical location of the Telegram Bot API reference.
+const DefaultURL = "https://core.telegram.org/bots/api"
+
+// DefaultMaxAge is how long a cached page is used without revalidation.
+const DefaultMaxAge = 24 * time.Hour
+
 var (
-	fetchURL       = "https://core.telegram.org/bots/api"
+	fetchURL       = DefaultURL
 	newRestyClient = func() *resty.Client { return resty.New() }
 )
 
-const (
-	cacheFile     = "spec_cache.html"
-	cacheLimit    = 24 * time.Hour
-	cacheFilePerm = 0o644
-)
+// Source provides the raw HTML of the Telegram Bot API documentation.
+type Source interface {
+	HTML(ctx context.Context) ([]byte, error)
+}
+
+// DefaultSource returns the source used when no explicit input is configured:
+// the official documentation fetched over HTTP and cached in DefaultCacheDir.
+func DefaultSource() Source {
+	return NewCachedSource(fetchURL, NewCache(DefaultCacheDir()))
+}
+
+// Document returns a goquery document built from the provided source. A nil
+// source falls back to DefaultSource.
+func Document(ctx context.Context, src Source) (*goquery.Document, error) {
+	if src == nil {
+		src = DefaultSource()
+	}
 
-// Document returns a goquery document sourced from the Telegram Bot API docs.
-// It uses a cached copy when available to avoid repeated network usage.
-func Document() (*goquery.Document, error) {
-	html, err := HTML()
+	html, err := src.HTML(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -38,52 +57,162 @@ func Document() (*goquery.Document, error) {
 	return doc, nil
 }
 
-// HTML retrieves the raw HTML of the Telegram Bot API docs, leveraging a local
-// cache to reduce the number of network requests.
-func HTML() ([]byte, error) {
-	if fileInfo, err := os.Stat(cacheFile); err == nil {
-		age := time.Since(fileInfo.ModTime())
-		if age < cacheLimit {
+// HTML retrieves the raw HTML of the Telegram Bot API docs from the default
+// source, leveraging the local cache to reduce the number of network requests.
+func HTML(ctx context.Context) ([]byte, error) {
+	return DefaultSource().HTML(ctx)
+}
+
+// BotAPIVersion extracts the version from the "Bot API X.Y" marker of the
+// documentation, returning an empty string when it is absent.
+func BotAPIVersion(doc *goquery.Document) string {
+	sel := doc.Find("p strong").FilterFunction(func(i int, s *goquery.Selection) bool {
+		return strings.HasPrefix(strings.TrimSpace(s.Text()), "Bot API ")
+	}).First()
+
+	text := strings.TrimSpace(sel.Text())
+	if text == "" {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(text, "Bot API "))
+}
+
+// CachedSource fetches a page over HTTP and keeps it in a Cache. Fresh entries
+// are served without network access; stale ones are revalidated with
+// If-None-Match/If-Modified-Since and used as a fallback when the network fails.
+type CachedSource struct {
+	URL    string
+	Cache  *Cache
+	MaxAge time.Duration
+	HTTP   HTTPOptions
+}
+
+// NewCachedSource builds a CachedSource for the given URL and cache using
+// DefaultMaxAge and DefaultHTTPOptions.
+func NewCachedSource(url string, cache *Cache) *CachedSource {
+	return &CachedSource{URL: url, Cache: cache, MaxAge: DefaultMaxAge, HTTP: DefaultHTTPOptions()}
+}
+
+// HTML returns the cached page when it is fresh enough, otherwise revalidates
+// or refetches it and refreshes the cache.
+//
+//nolint:cyclop,funlen // cache freshness, revalidation and fallback branches
+func (s *CachedSource) HTML(ctx context.Context) ([]byte, error) {
+	cached, err := s.Cache.Load(s.URL)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("fetcher: ignoring unreadable cache", "url", s.URL, "error", err)
+	}
+
+	if cached != nil {
+		age := time.Since(cached.Meta.FetchedAt)
+		if age < s.MaxAge {
 			slog.Info(
 				"fetcher: using cached spec",
-				"file", cacheFile,
+				"url", s.URL,
+				"version", cached.Meta.Version,
 				"age", age.Truncate(time.Second),
 			)
 
-			data, err := os.ReadFile(cacheFile)
-			if err != nil {
-				return nil, fmt.Errorf("read cache: %w", err)
-			}
-
-			return data, nil
+			return cached.HTML, nil
 		}
 
 		slog.Info(
-			"fetcher: cache expired, refetching",
-			"file", cacheFile,
+			"fetcher: cache expired, revalidating",
+			"url", s.URL,
 			"age", age.Truncate(time.Second),
-			"limit", cacheLimit,
+			"limit", s.MaxAge,
+		)
+	}
+
+	headers := make(map[string]string)
+	if cached != nil {
+		if cached.Meta.ETag != "" {
+			headers["If-None-Match"] = cached.Meta.ETag
+		}
+
+		if cached.Meta.LastModified != "" {
+			headers["If-Modified-Since"] = cached.Meta.LastModified
+		}
+	}
+
+	fallback := func(err error) ([]byte, error) {
+		if cached == nil || ctx.Err() != nil {
+			return nil, err
+		}
+
+		slog.Warn(
+			"fetcher: fetch failed, using stale cache",
+			"url", s.URL,
+			"version", cached.Meta.Version,
+			"fetched_at", cached.Meta.FetchedAt,
+			"error", err,
 		)
+
+		return cached.HTML, nil
+	}
+
+	resp, err := get(ctx, s.HTTP, s.URL, headers)
+	if err != nil {
+		return fallback(err)
+	}
+
+	if cached != nil && resp.StatusCode() == http.StatusNotModified {
+		slog.Info("fetcher: cached spec not modified", "url", s.URL, "version", cached.Meta.Version)
+
+		cached.Meta.FetchedAt = time.Now()
+		if err := s.Cache.StoreMeta(cached.Meta); err != nil {
+			return nil, err
+		}
+
+		return cached.HTML, nil
+	}
+
+	body := resp.Body()
+
+	slog.Info("fetcher: fetched spec", "url", s.URL, "bytes", len(body))
+
+	// Never let an error or captive-portal page into the cache.
+	version, err := inspectFetched(s.URL, body)
+	if err != nil {
+		return fallback(err)
+	}
+
+	entry := &CacheEntry{
+		Meta: CacheMeta{
+			URL:          s.URL,
+			ETag:         resp.Header().Get("ETag"),
+			LastModified: resp.Header().Get("Last-Modified"),
+			Version:      version,
+			FetchedAt:    time.Now(),
+		},
+		HTML: body,
+	}
+
+	if err := s.Cache.Store(entry); err != nil {
+		return nil, err
 	}
 
-	client := newRestyClient()
+	slog.Info("fetcher: updated cache", "dir", s.Cache.Dir, "version", entry.Meta.Version)
+
+	return body, nil
+}
 
-	resp, err := client.R().Get(fetchURL)
+func fetch(ctx context.Context, opts HTTPOptions, url string) ([]byte, error) {
+	resp, err := get(ctx, opts, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("fetch: %w", err)
+		return nil, err
 	}
 
 	slog.Info(
 		"fetcher: fetched spec",
-		"url", fetchURL,
+		"url", url,
 		"bytes", len(resp.Body()),
 	)
 
-	if err := os.WriteFile(cacheFile, resp.Body(), cacheFilePerm); err != nil {
-		return nil, fmt.Errorf("write cache: %w", err)
+	if _, err := inspectFetched(url, resp.Body()); err != nil {
+		return nil, err
 	}
 
-	slog.Info("fetcher: wrote cache file", "file", cacheFile)
-
 	return resp.Body(), nil
 }
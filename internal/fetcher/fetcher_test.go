@@ -2,48 +2,57 @@ package fetcher //nolint:testpackage // coverage test exercises internal cache b
 
 import (
 	"errors"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/go-resty/resty/v2"
 	"github.com/jarcoal/httpmock"
 )
 
-func useTempWorkDir(t *testing.T) string {
+const versionedHTML = `<html><body>
+<p><strong>Bot API 7.2</strong></p>
+<h3><a class="anchor" name="available-methods"></a>Available methods</h3>
+<p>fresh</p>
+</body></html>`
+
+func useMockClient(t *testing.T) {
 	t.Helper()
 
-	tmpDir := t.TempDir()
+	origNewClient := newRestyClient
 
-	wd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("getwd: %v", err)
-	}
+	client := resty.New()
+	httpmock.ActivateNonDefault(client.GetClient())
+	t.Cleanup(httpmock.DeactivateAndReset)
 
-	if err := os.Chdir(tmpDir); err != nil {
-		t.Fatalf("chdir: %v", err)
-	}
+	newRestyClient = func() *resty.Client { return client }
 
 	t.Cleanup(func() {
-		_ = os.Chdir(wd)
+		newRestyClient = origNewClient
 	})
-
-	return tmpDir
 }
 
 func TestDocumentUsesCache(t *testing.T) {
-	tmpDir := useTempWorkDir(t)
+	cache := NewCache(t.TempDir())
 
-	cachePath := filepath.Join(tmpDir, "spec_cache.html")
+	const (
+		url  = "https://example.com/bots/api"
+		html = `<html><body><p>cached</p></body></html>`
+	)
 
-	html := `<html><body><p>cached</p></body></html>`
-	if err := os.WriteFile(cachePath, []byte(html), 0o644); err != nil {
-		t.Fatalf("write cache: %v", err)
+	err := cache.Store(&CacheEntry{
+		Meta: CacheMeta{URL: url, FetchedAt: time.Now()},
+		HTML: []byte(html),
+	})
+	if err != nil {
+		t.Fatalf("store cache: %v", err)
 	}
 
-	doc, err := Document()
+	doc, err := Document(t.Context(), NewCachedSource(url, cache))
 	if err != nil {
 		t.Fatalf("Document returned error: %v", err)
 	}
@@ -54,62 +63,55 @@ func TestDocumentUsesCache(t *testing.T) {
 	}
 }
 
-func TestHTMLFetchesAndCaches(t *testing.T) {
-	tmpDir := useTempWorkDir(t)
-
-	origURL := fetchURL
-	origNewClient := newRestyClient
+func TestCachedSourceFetchesAndCaches(t *testing.T) {
+	useMockClient(t)
 
-	fetchURL = "https://example.com/bots/api"
+	const url = "https://example.com/bots/api"
 
-	client := resty.New()
-	httpmock.ActivateNonDefault(client.GetClient())
-	t.Cleanup(httpmock.DeactivateAndReset)
+	cache := NewCache(t.TempDir())
 
-	newRestyClient = func() *resty.Client {
-		return client
-	}
+	httpmock.RegisterResponder("GET", url, func(*http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(200, versionedHTML)
+		resp.Header.Set("ETag", `"v1"`)
+		resp.Header.Set("Last-Modified", "Mon, 01 Apr 2024 00:00:00 GMT")
 
-	t.Cleanup(func() {
-		fetchURL = origURL
-		newRestyClient = origNewClient
+		return resp, nil
 	})
 
-	const body = `<html><body><p>fresh</p></body></html>`
-	httpmock.RegisterResponder("GET", fetchURL, httpmock.NewStringResponder(200, body))
+	src := NewCachedSource(url, cache)
 
-	data, err := HTML()
+	data, err := src.HTML(t.Context())
 	if err != nil {
 		t.Fatalf("HTML: %v", err)
 	}
 
-	if string(data) != body {
-		t.Fatalf("expected fetched body %q, got %q", body, string(data))
+	if string(data) != versionedHTML {
+		t.Fatalf("expected fetched body, got %q", string(data))
 	}
 
-	info := httpmock.GetCallCountInfo()
-	if info["GET "+fetchURL] != 1 {
-		t.Fatalf("expected one fetch call, got counts %+v", info)
+	entry, err := cache.Load(url)
+	if err != nil {
+		t.Fatalf("load cache: %v", err)
 	}
 
-	cached, err := os.ReadFile(filepath.Join(tmpDir, cacheFile))
-	if err != nil {
-		t.Fatalf("read cache: %v", err)
+	if entry.Meta.ETag != `"v1"` || entry.Meta.LastModified == "" || entry.Meta.Version != "7.2" {
+		t.Fatalf("unexpected cache meta: %#v", entry.Meta)
 	}
 
-	if string(cached) != body {
-		t.Fatalf("expected cache contents %q, got %q", body, string(cached))
+	versions, err := cache.Versions(url)
+	if err != nil || len(versions) != 1 || versions[0] != "7.2" {
+		t.Fatalf("expected a 7.2 snapshot, got %v (err %v)", versions, err)
 	}
 
 	httpmock.ZeroCallCounters()
 
-	data, err = HTML()
+	data, err = src.HTML(t.Context())
 	if err != nil {
 		t.Fatalf("HTML second call: %v", err)
 	}
 
-	if string(data) != body {
-		t.Fatalf("expected cached body %q on second call, got %q", body, string(data))
+	if string(data) != versionedHTML {
+		t.Fatalf("expected cached body on second call, got %q", string(data))
 	}
 
 	if total := httpmock.GetTotalCallCount(); total != 0 {
@@ -117,127 +119,245 @@ func TestHTMLFetchesAndCaches(t *testing.T) {
 	}
 }
 
-func TestHTMLCacheExpired(t *testing.T) {
-	tmpDir := useTempWorkDir(t)
+func TestCachedSourceRevalidatesExpiredEntry(t *testing.T) {
+	useMockClient(t)
 
-	origURL := fetchURL
-	origNewClient := newRestyClient
-	fetchURL = "https://example.com/expired"
-	
-	client := resty.New()
-	httpmock.ActivateNonDefault(client.GetClient())
-	t.Cleanup(httpmock.DeactivateAndReset)
+	const url = "https://example.com/expired"
 
-	newRestyClient = func() *resty.Client { return client }
+	cache := NewCache(t.TempDir())
+	stale := time.Now().Add(-DefaultMaxAge - time.Hour)
 
-	t.Cleanup(func() {
-		fetchURL = origURL
-		newRestyClient = origNewClient
+	err := cache.Store(&CacheEntry{
+		Meta: CacheMeta{URL: url, ETag: `"v1"`, LastModified: "yesterday", Version: "7.1", FetchedAt: stale},
+		HTML: []byte("old"),
+	})
+	if err != nil {
+		t.Fatalf("store cache: %v", err)
+	}
+
+	httpmock.RegisterResponder("GET", url, func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("If-None-Match") != `"v1"` || req.Header.Get("If-Modified-Since") != "yesterday" {
+			t.Errorf("missing conditional headers: %v", req.Header)
+		}
+
+		return httpmock.NewStringResponse(http.StatusNotModified, ""), nil
 	})
 
-	// Create an expired cache file
-	cachePath := filepath.Join(tmpDir, cacheFile)
-	if err := os.WriteFile(cachePath, []byte("old"), 0o644); err != nil {
-		t.Fatalf("write cache: %v", err)
+	data, err := NewCachedSource(url, cache).HTML(t.Context())
+	if err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+
+	if string(data) != "old" {
+		t.Errorf("expected cached body after 304, got %q", string(data))
+	}
+
+	entry, err := cache.Load(url)
+	if err != nil {
+		t.Fatalf("load cache: %v", err)
+	}
+
+	if !entry.Meta.FetchedAt.After(stale) {
+		t.Error("expected revalidation to refresh the fetch time")
 	}
+}
+
+func TestCachedSourceRefetchesModifiedEntry(t *testing.T) {
+	useMockClient(t)
 
-	oldTime := time.Now().Add(-cacheLimit - time.Hour)
-	if err := os.Chtimes(cachePath, oldTime, oldTime); err != nil {
-		t.Fatalf("chtimes: %v", err)
+	const url = "https://example.com/modified"
+
+	cache := NewCache(t.TempDir())
+
+	err := cache.Store(&CacheEntry{
+		Meta: CacheMeta{URL: url, ETag: `"v1"`, Version: "7.1", FetchedAt: time.Now().Add(-2 * DefaultMaxAge)},
+		HTML: []byte("old"),
+	})
+	if err != nil {
+		t.Fatalf("store cache: %v", err)
 	}
 
-	const body = "fresh"
-	httpmock.RegisterResponder("GET", fetchURL, httpmock.NewStringResponder(200, body))
+	httpmock.RegisterResponder("GET", url, httpmock.NewStringResponder(200, versionedHTML))
 
-	data, err := HTML()
+	data, err := NewCachedSource(url, cache).HTML(t.Context())
 	if err != nil {
 		t.Fatalf("HTML: %v", err)
 	}
 
-	if string(data) != body {
+	if string(data) != versionedHTML {
 		t.Errorf("expected fresh body, got %q", string(data))
 	}
 
-	if httpmock.GetTotalCallCount() != 1 {
-		t.Error("expected network call for expired cache")
+	versions, err := cache.Versions(url)
+	if err != nil {
+		t.Fatalf("versions: %v", err)
+	}
+
+	if strings.Join(versions, ",") != "7.1,7.2" {
+		t.Fatalf("expected both snapshots to be kept, got %v", versions)
+	}
+
+	old, err := NewSnapshotSource(cache, url, "7.1").HTML(t.Context())
+	if err != nil || string(old) != "old" {
+		t.Fatalf("expected historical snapshot, got %q (err %v)", string(old), err)
 	}
 }
 
-func TestHTMLFetchError(t *testing.T) {
-	useTempWorkDir(t)
+func TestCachedSourceFetchError(t *testing.T) {
+	useMockClient(t)
 
-	origURL := fetchURL
-	origNewClient := newRestyClient
+	const url = "https://example.com/fail"
 
-	fetchURL = "https://example.com/fail"
+	cache := NewCache(t.TempDir())
 
-	client := resty.New()
-	httpmock.ActivateNonDefault(client.GetClient())
-	t.Cleanup(httpmock.DeactivateAndReset)
+	httpmock.RegisterResponder("GET", url, httpmock.NewErrorResponder(errors.New("network down")))
+
+	src := NewCachedSource(url, cache)
+	src.HTTP = fastHTTPOptions()
 
-	newRestyClient = func() *resty.Client {
-		return client
+	if _, err := src.HTML(t.Context()); err == nil {
+		t.Fatal("expected HTML to return error on fetch failure")
 	}
 
-	t.Cleanup(func() {
-		fetchURL = origURL
-		newRestyClient = origNewClient
-	})
+	if _, err := cache.Load(url); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no cache entry on fetch failure, got %v", err)
+	}
+}
 
-	httpmock.RegisterResponder("GET", fetchURL, httpmock.NewErrorResponder(errors.New("network down")))
+func TestCachedSourceWriteCacheError(t *testing.T) {
+	useMockClient(t)
 
-	if _, err := HTML(); err == nil {
-		t.Fatal("expected HTML to return error on fetch failure")
+	const url = "https://example.com/write-fail"
+
+	// A regular file where the cache directory should be makes MkdirAll fail.
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatalf("write blocker: %v", err)
 	}
 
-	if _, err := os.Stat(cacheFile); !os.IsNotExist(err) {
-		t.Fatalf("expected no cache file on fetch failure, stat err = %v", err)
+	httpmock.RegisterResponder("GET", url, httpmock.NewStringResponder(200, versionedHTML))
+
+	if _, err := NewCachedSource(url, NewCache(blocker)).HTML(t.Context()); err == nil {
+		t.Fatal("expected error writing cache below a regular file")
 	}
 }
 
-func TestHTMLReadCacheError(t *testing.T) {
-	tmpDir := useTempWorkDir(t)
+func TestCacheStoreReplacesFiles(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	dir := cache.urlDir(DefaultURL)
+
+	for _, html := range []string{versionedHTML, strings.Replace(versionedHTML, "fresh", "newer", 1)} {
+		entry := &CacheEntry{Meta: CacheMeta{URL: DefaultURL, Version: "7.2"}, HTML: []byte(html)}
+		if err := cache.Store(entry); err != nil {
+			t.Fatalf("store: %v", err)
+		}
+	}
+
+	cached, err := cache.Load(DefaultURL)
+	if err != nil || !strings.Contains(string(cached.HTML), "newer") {
+		t.Fatalf("expected the page to be replaced, got %q (err %v)", cached.HTML, err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, cachePageFile))
+	if err != nil || info.Mode().Perm() != cacheFilePerm {
+		t.Fatalf("expected page mode %v, got %v (err %v)", os.FileMode(cacheFilePerm), info, err)
+	}
+
+	// A directory in place of the snapshot makes its rename fail.
+	blocked := &CacheEntry{Meta: CacheMeta{URL: DefaultURL, Version: "7.3"}, HTML: []byte(versionedHTML)}
+	if err := os.MkdirAll(filepath.Join(dir, cacheVersionsDir, "7.3"+snapshotExt, "x"), 0o755); err != nil {
+		t.Fatalf("create blocker: %v", err)
+	}
 
-	// Create a directory with the cache file name to cause ReadFile to fail
-	if err := os.Mkdir(cacheFile, 0o755); err != nil {
-		t.Fatalf("mkdir: %v", err)
+	if err := cache.Store(blocked); err == nil {
+		t.Fatal("expected storing over a directory to fail")
 	}
 
-	if _, err := HTML(); err == nil {
-		t.Fatal("expected error reading cache directory as file")
+	for _, sub := range []string{dir, filepath.Join(dir, cacheVersionsDir)} {
+		entries, err := os.ReadDir(sub)
+		if err != nil {
+			t.Fatalf("read dir: %v", err)
+		}
+
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), ".tmp") {
+				t.Fatalf("expected temporary files to be removed, found %s", e.Name())
+			}
+		}
 	}
-	
-	// Cleanup for other tests
-	os.RemoveAll(filepath.Join(tmpDir, cacheFile))
 }
 
-func TestHTMLWriteCacheError(t *testing.T) {
-	tmpDir := useTempWorkDir(t)
+func TestSnapshotSourceMissing(t *testing.T) {
+	cache := NewCache(t.TempDir())
 
-	origURL := fetchURL
-	origNewClient := newRestyClient
-	fetchURL = "https://example.com/write-fail"
-	client := resty.New()
-	httpmock.ActivateNonDefault(client.GetClient())
-	t.Cleanup(httpmock.DeactivateAndReset)
+	for _, version := range []string{"6.0", "../etc", ""} {
+		if _, err := NewSnapshotSource(cache, DefaultURL, version).HTML(t.Context()); !errors.Is(err, ErrSnapshotNotFound) {
+			t.Fatalf("expected ErrSnapshotNotFound for %q, got %v", version, err)
+		}
+	}
 
-	newRestyClient = func() *resty.Client { return client }
+	versions, err := cache.Versions(DefaultURL)
+	if err != nil || len(versions) != 0 {
+		t.Fatalf("expected no versions, got %v (err %v)", versions, err)
+	}
+}
 
-	t.Cleanup(func() {
-		fetchURL = origURL
-		newRestyClient = origNewClient
-	})
+func TestCacheVersionsOrder(t *testing.T) {
+	cache := NewCache(t.TempDir())
+
+	for _, version := range []string{"7.10", "7.9", "10.0", "7.2"} {
+		entry := &CacheEntry{Meta: CacheMeta{URL: DefaultURL, Version: version}, HTML: []byte(versionedHTML)}
+		if err := cache.Store(entry); err != nil {
+			t.Fatalf("store %s: %v", version, err)
+		}
+	}
+
+	versions, err := cache.Versions(DefaultURL)
+	if err != nil {
+		t.Fatalf("versions: %v", err)
+	}
 
-	httpmock.RegisterResponder("GET", fetchURL, httpmock.NewStringResponder(200, "data"))
+	if strings.Join(versions, ",") != "7.2,7.9,7.10,10.0" {
+		t.Fatalf("expected versions in numeric order, got %v", versions)
+	}
 
-	// Create a directory where the file should be written to cause WriteFile to fail
-	if err := os.Mkdir(cacheFile, 0o755); err != nil {
-		t.Fatalf("mkdir: %v", err)
+	_, err = NewSnapshotSource(cache, DefaultURL, "6.0").HTML(t.Context())
+	if !errors.Is(err, ErrSnapshotNotFound) || !strings.Contains(err.Error(), "(cached: 7.2, 7.9, 7.10, 10.0)") {
+		t.Fatalf("expected the cached versions in the error, got %v", err)
 	}
+}
 
-	if _, err := HTML(); err == nil {
-		t.Fatal("expected error writing cache to a directory")
+func TestDefaultCacheDir(t *testing.T) {
+	t.Setenv(CacheDirEnv, "/tmp/custom-cache")
+
+	if dir := DefaultCacheDir(); dir != "/tmp/custom-cache" {
+		t.Fatalf("expected env override, got %q", dir)
 	}
 
-	os.RemoveAll(filepath.Join(tmpDir, cacheFile))
-}
\ No newline at end of file
+	t.Setenv(CacheDirEnv, "")
+
+	if dir := DefaultCacheDir(); filepath.Base(dir) != "tgbotspec" {
+		t.Fatalf("expected tgbotspec cache dir, got %q", dir)
+	}
+}
+
+func TestBotAPIVersion(t *testing.T) {
+	if v := BotAPIVersion(mustDocument(t, versionedHTML)); v != "7.2" {
+		t.Fatalf("expected version 7.2, got %q", v)
+	}
+
+	if v := BotAPIVersion(mustDocument(t, `<html><body></body></html>`)); v != "" {
+		t.Fatalf("expected empty version, got %q", v)
+	}
+}
+
+func mustDocument(t *testing.T, html string) *goquery.Document {
+	t.Helper()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("parse html: %v", err)
+	}
+
+	return doc
+}
@@ -0,0 +1,117 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultRetries      = 3
+	defaultRetryWait    = time.Second
+	defaultRetryMaxWait = 30 * time.Second
+)
+
+// HTTPOptions tunes network access of the HTTP-backed sources.
+type HTTPOptions struct {
+	// Timeout bounds a single request attempt; zero disables it.
+	Timeout time.Duration
+	// Retries is the number of additional attempts after a transient failure
+	// (connection errors, 5xx and 429 responses).
+	Retries int
+	// RetryWait and RetryMaxWait bound the exponential backoff between
+	// attempts. A Retry-After header is honoured up to RetryMaxWait.
+	RetryWait    time.Duration
+	RetryMaxWait time.Duration
+}
+
+// DefaultHTTPOptions returns the options used by the source constructors.
+func DefaultHTTPOptions() HTTPOptions {
+	return HTTPOptions{
+		Timeout:      defaultTimeout,
+		Retries:      defaultRetries,
+		RetryWait:    defaultRetryWait,
+		RetryMaxWait: defaultRetryMaxWait,
+	}
+}
+
+func newClient(opts HTTPOptions) *resty.Client {
+	return newRestyClient().
+		SetTimeout(opts.Timeout).
+		SetRetryCount(opts.Retries).
+		SetRetryWaitTime(opts.RetryWait).
+		SetRetryMaxWaitTime(opts.RetryMaxWait).
+		AddRetryCondition(isTransient).
+		SetRetryAfter(retryAfter).
+		AddRetryHook(func(resp *resty.Response, err error) {
+			status := 0
+			if resp != nil {
+				status = resp.StatusCode()
+			}
+
+			slog.Warn("fetcher: transient failure, retrying", "status", status, "error", err)
+		})
+}
+
+// get performs a GET request bound to ctx with the given extra headers. Any
+// response other than 2xx or 304 Not Modified is reported as a *StatusError.
+func get(ctx context.Context, opts HTTPOptions, url string, headers map[string]string) (*resty.Response, error) {
+	resp, err := newClient(opts).R().
+		SetContext(ctx).
+		SetHeaders(headers).
+		Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	if !resp.IsSuccess() && resp.StatusCode() != http.StatusNotModified {
+		return nil, &StatusError{URL: url, StatusCode: resp.StatusCode(), Status: resp.Status()}
+	}
+
+	return resp, nil
+}
+
+// isTransient reports whether a request should be retried. Cancellation and
+// an expired deadline or attempt timeout are final, so Ctrl-C does not wait
+// out the backoff schedule.
+func isTransient(resp *resty.Response, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if err != nil || resp == nil {
+		return true
+	}
+
+	code := resp.StatusCode()
+
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// retryAfter honours the Retry-After header (seconds or HTTP date); zero
+// lets resty fall back to exponential backoff.
+func retryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	value := resp.Header().Get("Retry-After")
+	if value == "" {
+		return 0, nil
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait, nil
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,165 @@
+package fetcher //nolint:testpackage // tests swap internal HTTP client hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/jarcoal/httpmock"
+)
+
+func fastHTTPOptions() HTTPOptions {
+	return HTTPOptions{
+		Timeout:      time.Second,
+		Retries:      2,
+		RetryWait:    time.Millisecond,
+		RetryMaxWait: 5 * time.Millisecond,
+	}
+}
+
+func TestURLSourceRetriesTransientFailures(t *testing.T) {
+	useMockClient(t)
+
+	const url = "https://example.com/flaky"
+
+	httpmock.RegisterResponder("GET", url, httpmock.ResponderFromMultipleResponses([]*http.Response{
+		httpmock.NewStringResponse(http.StatusServiceUnavailable, "busy"),
+		httpmock.NewStringResponse(http.StatusTooManyRequests, "slow down"),
+		httpmock.NewStringResponse(http.StatusOK, versionedHTML),
+	}))
+
+	src := &URLSource{URL: url, HTTP: fastHTTPOptions()}
+
+	data, err := src.HTML(t.Context())
+	if err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+
+	if string(data) != versionedHTML {
+		t.Fatalf("expected body after retries, got %q", string(data))
+	}
+
+	if calls := httpmock.GetTotalCallCount(); calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestURLSourceHonoursCancellation(t *testing.T) {
+	useMockClient(t)
+
+	const url = "https://example.com/cancelled"
+
+	httpmock.RegisterResponder("GET", url, httpmock.NewErrorResponder(errors.New("connection reset")))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	src := &URLSource{URL: url, HTTP: fastHTTPOptions()}
+	if _, err := src.HTML(ctx); err == nil {
+		t.Fatal("expected error for cancelled context")
+	}
+
+	if calls := httpmock.GetTotalCallCount(); calls > 1 {
+		t.Fatalf("expected no retries after cancellation, got %d calls", calls)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	status := func(code int) *resty.Response {
+		return &resty.Response{RawResponse: &http.Response{StatusCode: code}}
+	}
+
+	tests := []struct {
+		name string
+		resp *resty.Response
+		err  error
+		want bool
+	}{
+		{name: "connection error", err: errors.New("connection reset"), want: true},
+		{name: "canceled", err: fmt.Errorf("get: %w", context.Canceled), want: false},
+		{name: "deadline", err: fmt.Errorf("get: %w", context.DeadlineExceeded), want: false},
+		{name: "server error", resp: status(http.StatusBadGateway), want: true},
+		{name: "rate limited", resp: status(http.StatusTooManyRequests), want: true},
+		{name: "not found", resp: status(http.StatusNotFound), want: false},
+		{name: "ok", resp: status(http.StatusOK), want: false},
+	}
+
+	for _, tc := range tests {
+		if got := isTransient(tc.resp, tc.err); got != tc.want {
+			t.Errorf("%s: isTransient = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCachedSourceFallsBackToStaleCache(t *testing.T) {
+	useMockClient(t)
+
+	const url = "https://example.com/offline"
+
+	cache := NewCache(t.TempDir())
+
+	err := cache.Store(&CacheEntry{
+		Meta: CacheMeta{URL: url, Version: "7.1", FetchedAt: time.Now().Add(-2 * DefaultMaxAge)},
+		HTML: []byte("stale"),
+	})
+	if err != nil {
+		t.Fatalf("store cache: %v", err)
+	}
+
+	httpmock.RegisterResponder("GET", url, httpmock.NewErrorResponder(errors.New("connection reset")))
+
+	src := NewCachedSource(url, cache)
+	src.HTTP = fastHTTPOptions()
+
+	data, err := src.HTML(t.Context())
+	if err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+
+	if string(data) != "stale" {
+		t.Fatalf("expected stale cache fallback, got %q", string(data))
+	}
+
+	if calls := httpmock.GetTotalCallCount(); calls != 3 {
+		t.Fatalf("expected the request to be retried before falling back, got %d calls", calls)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   func(time.Duration) bool
+	}{
+		{name: "missing", header: "", want: func(d time.Duration) bool { return d == 0 }},
+		{name: "seconds", header: "3", want: func(d time.Duration) bool { return d == 3*time.Second }},
+		{name: "invalid", header: "soon", want: func(d time.Duration) bool { return d == 0 }},
+		{
+			name:   "http date",
+			header: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat),
+			want:   func(d time.Duration) bool { return d > 59*time.Minute && d <= time.Hour },
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &resty.Response{RawResponse: &http.Response{Header: http.Header{}}}
+			if tc.header != "" {
+				resp.RawResponse.Header.Set("Retry-After", tc.header)
+			}
+
+			got, err := retryAfter(nil, resp)
+			if err != nil {
+				t.Fatalf("retryAfter returned error: %v", err)
+			}
+
+			if !tc.want(got) {
+				t.Fatalf("unexpected wait %v for header %q", got, tc.header)
+			}
+		})
+	}
+}
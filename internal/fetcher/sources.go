@@ -0,0 +1,66 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// FileSource reads the documentation from a local HTML file, e.g. a pinned
+// snapshot checked into a repository.
+type FileSource struct {
+	Path string
+}
+
+// NewFileSource builds a FileSource for the given path.
+func NewFileSource(path string) *FileSource {
+	return &FileSource{Path: path}
+}
+
+// HTML returns the contents of the file.
+func (s *FileSource) HTML(_ context.Context) ([]byte, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read input file: %w", err)
+	}
+
+	return data, nil
+}
+
+// ReaderSource reads the documentation from an arbitrary reader such as stdin.
+type ReaderSource struct {
+	Reader io.Reader
+}
+
+// NewReaderSource builds a ReaderSource consuming the given reader.
+func NewReaderSource(r io.Reader) *ReaderSource {
+	return &ReaderSource{Reader: r}
+}
+
+// HTML reads the reader until EOF.
+func (s *ReaderSource) HTML(_ context.Context) ([]byte, error) {
+	data, err := io.ReadAll(s.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("read input: %w", err)
+	}
+
+	return data, nil
+}
+
+// URLSource fetches the documentation from an arbitrary URL without caching,
+// e.g. a mirror, an archive.org snapshot or a local Bot API server.
+type URLSource struct {
+	URL  string
+	HTTP HTTPOptions
+}
+
+// NewURLSource builds a URLSource for the given URL using DefaultHTTPOptions.
+func NewURLSource(url string) *URLSource {
+	return &URLSource{URL: url, HTTP: DefaultHTTPOptions()}
+}
+
+// HTML fetches the page over HTTP.
+func (s *URLSource) HTML(ctx context.Context) ([]byte, error) {
+	return fetch(ctx, s.HTTP, s.URL)
+}
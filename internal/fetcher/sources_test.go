@@ -0,0 +1,71 @@
+package fetcher //nolint:testpackage // tests swap internal HTTP client hooks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.html")
+
+	const html = `<html><body><p>snapshot</p></body></html>`
+	if err := os.WriteFile(path, []byte(html), 0o600); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+
+	doc, err := Document(t.Context(), NewFileSource(path))
+	if err != nil {
+		t.Fatalf("Document returned error: %v", err)
+	}
+
+	if text := strings.TrimSpace(doc.Find("p").Text()); text != "snapshot" {
+		t.Fatalf("expected snapshot contents, got %q", text)
+	}
+
+	if _, err := NewFileSource(filepath.Join(t.TempDir(), "missing.html")).HTML(t.Context()); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("boom")
+}
+
+func TestReaderSource(t *testing.T) {
+	data, err := NewReaderSource(strings.NewReader("<p>stdin</p>")).HTML(t.Context())
+	if err != nil {
+		t.Fatalf("HTML returned error: %v", err)
+	}
+
+	if string(data) != "<p>stdin</p>" {
+		t.Fatalf("unexpected data %q", string(data))
+	}
+
+	if _, err := NewReaderSource(failingReader{}).HTML(t.Context()); err == nil {
+		t.Fatal("expected error from failing reader")
+	}
+}
+
+func TestURLSource(t *testing.T) {
+	useMockClient(t)
+
+	const url = "https://mirror.example.com/bots/api"
+
+	httpmock.RegisterResponder("GET", url, httpmock.NewStringResponder(200, versionedHTML))
+
+	data, err := NewURLSource(url).HTML(t.Context())
+	if err != nil {
+		t.Fatalf("HTML returned error: %v", err)
+	}
+
+	if string(data) != versionedHTML {
+		t.Fatalf("unexpected body %q", string(data))
+	}
+}
@@ -0,0 +1,57 @@
+package fetcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// methodsSectionAnchor names the section every Bot API reference page has.
+const methodsSectionAnchor = "available-methods"
+
+// Validate checks that html looks like the Telegram Bot API reference: it must
+// carry the "Bot API X.Y" marker and the available-methods section. The
+// returned error is an *InvalidDocumentError.
+func Validate(html []byte) error {
+	_, err := inspect(html)
+
+	return err
+}
+
+// inspect validates html and returns the detected Bot API version.
+func inspect(html []byte) (string, error) {
+	if len(bytes.TrimSpace(html)) == 0 {
+		return "", &InvalidDocumentError{Reason: "empty document"}
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return "", &InvalidDocumentError{Reason: fmt.Sprintf("parse html: %v", err)}
+	}
+
+	version := BotAPIVersion(doc)
+	if version == "" {
+		return "", &InvalidDocumentError{Reason: `missing "Bot API X.Y" version marker`}
+	}
+
+	selector := fmt.Sprintf("a.anchor[name='%s'], #%s", methodsSectionAnchor, methodsSectionAnchor)
+	if doc.Find(selector).Length() == 0 {
+		return "", &InvalidDocumentError{Reason: "missing " + methodsSectionAnchor + " section"}
+	}
+
+	return version, nil
+}
+
+// inspectFetched validates a fetched page and attributes failures to url.
+func inspectFetched(url string, html []byte) (string, error) {
+	version, err := inspect(html)
+
+	var invalid *InvalidDocumentError
+	if errors.As(err, &invalid) {
+		invalid.URL = url
+	}
+
+	return version, err
+}
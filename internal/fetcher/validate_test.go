@@ -0,0 +1,146 @@
+package fetcher //nolint:testpackage // tests swap internal HTTP client hooks
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		html   string
+		reason string
+	}{
+		{name: "valid", html: versionedHTML},
+		{name: "empty", html: "  \n", reason: "empty document"},
+		{
+			name:   "captive portal",
+			html:   `<html><body><h1>Please log in to continue</h1></body></html>`,
+			reason: "version marker",
+		},
+		{
+			name:   "missing methods",
+			html:   `<html><body><p><strong>Bot API 7.2</strong></p></body></html>`,
+			reason: "available-methods",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate([]byte(tc.html))
+			if tc.reason == "" {
+				if err != nil {
+					t.Fatalf("expected valid document, got %v", err)
+				}
+
+				return
+			}
+
+			var invalid *InvalidDocumentError
+			if !errors.As(err, &invalid) || !errors.Is(err, ErrInvalidDocument) {
+				t.Fatalf("expected InvalidDocumentError, got %v", err)
+			}
+
+			if !strings.Contains(invalid.Reason, tc.reason) {
+				t.Fatalf("expected reason to mention %q, got %q", tc.reason, invalid.Reason)
+			}
+		})
+	}
+}
+
+func TestCachedSourceRejectsErrorStatus(t *testing.T) {
+	useMockClient(t)
+
+	const url = "https://example.com/not-found"
+
+	cache := NewCache(t.TempDir())
+
+	httpmock.RegisterResponder("GET", url, httpmock.NewStringResponder(http.StatusNotFound, versionedHTML))
+
+	_, err := NewCachedSource(url, cache).HTML(t.Context())
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected StatusError 404, got %v", err)
+	}
+
+	if !errors.Is(err, ErrUnexpectedStatus) {
+		t.Fatalf("expected ErrUnexpectedStatus, got %v", err)
+	}
+
+	if _, err := cache.Load(url); err == nil {
+		t.Fatal("expected error response not to be cached")
+	}
+}
+
+func TestCachedSourceRejectsInvalidDocument(t *testing.T) {
+	useMockClient(t)
+
+	const url = "https://example.com/portal"
+
+	cache := NewCache(t.TempDir())
+
+	httpmock.RegisterResponder("GET", url, httpmock.NewStringResponder(http.StatusOK, "<html>Wi-Fi login</html>"))
+
+	_, err := NewCachedSource(url, cache).HTML(t.Context())
+	if !errors.Is(err, ErrInvalidDocument) {
+		t.Fatalf("expected ErrInvalidDocument, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), url) {
+		t.Fatalf("expected error to name the URL, got %v", err)
+	}
+
+	if _, err := cache.Load(url); err == nil {
+		t.Fatal("expected invalid document not to be cached")
+	}
+}
+
+func TestCachedSourceKeepsCacheOnInvalidDocument(t *testing.T) {
+	useMockClient(t)
+
+	const url = "https://example.com/portal-stale"
+
+	cache := NewCache(t.TempDir())
+
+	err := cache.Store(&CacheEntry{
+		Meta: CacheMeta{URL: url, Version: "7.2", FetchedAt: time.Now().Add(-2 * DefaultMaxAge)},
+		HTML: []byte(versionedHTML),
+	})
+	if err != nil {
+		t.Fatalf("store cache: %v", err)
+	}
+
+	httpmock.RegisterResponder("GET", url, httpmock.NewStringResponder(http.StatusOK, "<html>Wi-Fi login</html>"))
+
+	data, err := NewCachedSource(url, cache).HTML(t.Context())
+	if err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+
+	if string(data) != versionedHTML {
+		t.Fatalf("expected previous cache contents, got %q", string(data))
+	}
+
+	entry, err := cache.Load(url)
+	if err != nil || string(entry.HTML) != versionedHTML {
+		t.Fatalf("expected cache to stay intact, got %v", err)
+	}
+}
+
+func TestURLSourceRejectsInvalidDocument(t *testing.T) {
+	useMockClient(t)
+
+	const url = "https://example.com/empty"
+
+	httpmock.RegisterResponder("GET", url, httpmock.NewStringResponder(http.StatusOK, ""))
+
+	if _, err := NewURLSource(url).HTML(t.Context()); !errors.Is(err, ErrInvalidDocument) {
+		t.Fatalf("expected ErrInvalidDocument, got %v", err)
+	}
+}
@@ -0,0 +1,344 @@
+// Package ir defines the intermediate representation (IR) of the parsed Bot
+// API documentation: the types and methods recovered from the HTML, before
+// they are turned into an OpenAPI document.
+//
+// The IR is serialized as JSON and versioned by Version. Within a version the
+// format only grows: fields may be added, but are never renamed, removed or
+// given a different meaning. A reader must ignore fields it does not know.
+//
+//	{
+//	  "version": 1,
+//	  "title": "Telegram Bot API",
+//	  "api_version": "7.2",
+//	  "types": [{"anchor": "user", "name": "User", "tag": "Available types",
+//	    "description": ["This object represents a Telegram user or bot."],
+//	    "fields": [{"name": "id", "type": "Integer", "required": true,
+//	      "description": "Unique identifier for this user or bot.", "int64": true}]}],
+//	  "methods": [{"anchor": "getme", "name": "getMe", "tags": ["Available methods"],
+//	    "description": ["A simple method for testing your bot's authentication token."],
+//	    "return": "User"}]
+//	}
+package ir
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/metalagman/tgbotspec/internal/parser"
+)
+
+// Version is the IR format version written by this build. Documents with a
+// different version are rejected by Read.
+const Version = 1
+
+// ErrUnsupportedVersion is returned by Read for an IR of another version.
+var ErrUnsupportedVersion = errors.New("unsupported IR version")
+
+// Document is the parsed documentation.
+type Document struct {
+	Version int `json:"version"`
+	// Title is the title of the documentation page, e.g. "Telegram Bot API".
+	Title string `json:"title"`
+	// APIVersion is the Bot API version, e.g. "7.2"; empty when unknown.
+	APIVersion string `json:"api_version,omitempty"`
+	// Types and Methods are sorted by name.
+	Types   []Type   `json:"types"`
+	Methods []Method `json:"methods"`
+	// Changelog lists the releases of the "Recent changes" section, newest
+	// first.
+	Changelog []Release `json:"changelog,omitempty"`
+}
+
+// Type is an object definition, e.g. Message.
+type Type struct {
+	// Anchor is the id of the section in the documentation page.
+	Anchor string `json:"anchor"`
+	Name   string `json:"name"`
+	// Tag is the documentation section the type is listed in.
+	Tag string `json:"tag,omitempty"`
+	// Description and Notes hold Markdown paragraphs; Notes follow the
+	// fields table.
+	Description []string `json:"description,omitempty"`
+	Notes       []string `json:"notes,omitempty"`
+	// Fields are the object fields in documented order.
+	Fields []Field `json:"fields,omitempty"`
+	// OneOf lists the variants of an abstract type such as ChatMember.
+	OneOf []string `json:"one_of,omitempty"`
+}
+
+// Method is an API method, e.g. sendMessage.
+type Method struct {
+	Anchor      string   `json:"anchor"`
+	Name        string   `json:"name"`
+	Tags        []string `json:"tags,omitempty"`
+	Description []string `json:"description,omitempty"`
+	Notes       []string `json:"notes,omitempty"`
+	// Params are the parameters in documented order.
+	Params []Field `json:"params,omitempty"`
+	// Return is the documented return type, e.g. "Array of Update"; empty
+	// when it could not be parsed.
+	Return     string `json:"return,omitempty"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+	ReplacedBy string `json:"replaced_by,omitempty"`
+}
+
+// Field is a field of a type or a parameter of a method.
+type Field struct {
+	Name string `json:"name"`
+	// Type is the type as written in the docs, e.g. "Integer or String".
+	Type        string `json:"type,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+	Annotations
+}
+
+// Annotations are the schema hints recovered from a field description; see
+// parser.Annotations.
+type Annotations struct {
+	Enum       []string `json:"enum,omitempty"`
+	Int64      bool     `json:"int64,omitempty"`
+	MinLength  *int     `json:"min_length,omitempty"`
+	MaxLength  *int     `json:"max_length,omitempty"`
+	Min        *int     `json:"min,omitempty"`
+	Max        *int     `json:"max,omitempty"`
+	Default    string   `json:"default,omitempty"`
+	Deprecated bool     `json:"deprecated,omitempty"`
+	ReplacedBy string   `json:"replaced_by,omitempty"`
+}
+
+// Release is a Bot API release of the changelog.
+type Release struct {
+	Version string `json:"version"`
+	// Date is the release date as YYYY-MM-DD, or as written in the docs.
+	Date  string       `json:"date,omitempty"`
+	Items []ChangeItem `json:"items,omitempty"`
+}
+
+// ChangeItem is one bullet of a release.
+type ChangeItem struct {
+	// Text is the Markdown of the bullet.
+	Text string `json:"text"`
+	// Refs names the types and methods the bullet links to, Added those it
+	// introduces.
+	Refs  []string `json:"refs,omitempty"`
+	Added []string `json:"added,omitempty"`
+}
+
+// New builds the IR of parsed documentation.
+func New(
+	title, apiVersion string,
+	types []parser.TypeDef,
+	methods []parser.MethodDef,
+	changelog []parser.Release,
+) *Document {
+	doc := &Document{
+		Version:    Version,
+		Title:      title,
+		APIVersion: apiVersion,
+		Types:      make([]Type, 0, len(types)),
+		Methods:    make([]Method, 0, len(methods)),
+	}
+
+	for _, r := range changelog {
+		release := Release{Version: r.Version, Date: r.Date}
+		for _, item := range r.Items {
+			release.Items = append(release.Items, ChangeItem(item))
+		}
+
+		doc.Changelog = append(doc.Changelog, release)
+	}
+
+	for _, t := range types {
+		typ := Type{
+			Anchor:      t.Anchor,
+			Name:        t.Name,
+			Tag:         t.Tag,
+			Description: t.Description,
+			Notes:       t.Notes,
+			OneOf:       t.OneOf,
+		}
+
+		for _, f := range t.Fields {
+			typ.Fields = append(typ.Fields, newField(f.Name, f.TypeRef, f.Required, f.Description, f.Annotations))
+		}
+
+		doc.Types = append(doc.Types, typ)
+	}
+
+	for _, m := range methods {
+		method := Method{
+			Anchor:      m.Anchor,
+			Name:        m.Name,
+			Tags:        m.Tags,
+			Description: m.Description,
+			Notes:       m.Notes,
+			Return:      rawType(m.Return),
+			Deprecated:  m.Deprecated,
+			ReplacedBy:  m.ReplacedBy,
+		}
+
+		for _, p := range m.Params {
+			method.Params = append(method.Params, newField(p.Name, p.TypeRef, p.Required, p.Description, p.Annotations))
+		}
+
+		doc.Methods = append(doc.Methods, method)
+	}
+
+	return doc
+}
+
+// TypeDefs returns the types as parser definitions.
+func (d *Document) TypeDefs() []parser.TypeDef {
+	res := make([]parser.TypeDef, 0, len(d.Types))
+
+	for _, t := range d.Types {
+		def := parser.TypeDef{
+			Anchor:      t.Anchor,
+			Name:        t.Name,
+			Tag:         t.Tag,
+			Description: t.Description,
+			Notes:       t.Notes,
+			OneOf:       t.OneOf,
+		}
+
+		for _, f := range t.Fields {
+			def.Fields = append(def.Fields, parser.TypeFieldDef{
+				Name:        f.Name,
+				TypeRef:     typeRef(f.Type),
+				Required:    f.Required,
+				Description: f.Description,
+				Annotations: f.parserAnnotations(),
+			})
+		}
+
+		res = append(res, def)
+	}
+
+	return res
+}
+
+// MethodDefs returns the methods as parser definitions.
+func (d *Document) MethodDefs() []parser.MethodDef {
+	res := make([]parser.MethodDef, 0, len(d.Methods))
+
+	for _, m := range d.Methods {
+		def := parser.MethodDef{
+			Anchor:      m.Anchor,
+			Name:        m.Name,
+			Tags:        m.Tags,
+			Description: m.Description,
+			Notes:       m.Notes,
+			Return:      typeRef(m.Return),
+			Deprecation: parser.Deprecation{Deprecated: m.Deprecated, ReplacedBy: m.ReplacedBy},
+		}
+
+		for _, p := range m.Params {
+			def.Params = append(def.Params, parser.MethodParamDef{
+				Name:        p.Name,
+				TypeRef:     typeRef(p.Type),
+				Required:    p.Required,
+				Description: p.Description,
+				Annotations: p.parserAnnotations(),
+			})
+		}
+
+		res = append(res, def)
+	}
+
+	return res
+}
+
+// Releases returns the changelog as parser releases.
+func (d *Document) Releases() []parser.Release {
+	var res []parser.Release
+
+	for _, r := range d.Changelog {
+		release := parser.Release{Version: r.Version, Date: r.Date}
+		for _, item := range r.Items {
+			release.Items = append(release.Items, parser.ChangeItem(item))
+		}
+
+		res = append(res, release)
+	}
+
+	return res
+}
+
+// WriteJSON writes the document as indented JSON.
+func (d *Document) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(d); err != nil {
+		return fmt.Errorf("encode IR: %w", err)
+	}
+
+	return nil
+}
+
+// Read decodes an IR document written by WriteJSON.
+func Read(r io.Reader) (*Document, error) {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode IR: %w", err)
+	}
+
+	if doc.Version != Version {
+		return nil, fmt.Errorf("%w %d (want %d)", ErrUnsupportedVersion, doc.Version, Version)
+	}
+
+	return &doc, nil
+}
+
+func newField(name string, ref *parser.TypeRef, required bool, description string, a parser.Annotations) Field {
+	return Field{
+		Name:        name,
+		Type:        rawType(ref),
+		Required:    required,
+		Description: description,
+		Annotations: Annotations{
+			Enum:       a.Enum,
+			Int64:      a.Int64,
+			MinLength:  a.MinLength,
+			MaxLength:  a.MaxLength,
+			Min:        a.Min,
+			Max:        a.Max,
+			Default:    a.Default,
+			Deprecated: a.Deprecated,
+			ReplacedBy: a.ReplacedBy,
+		},
+	}
+}
+
+func (f Field) parserAnnotations() parser.Annotations {
+	return parser.Annotations{
+		Enum:  f.Enum,
+		Int64: f.Int64,
+		Constraints: parser.Constraints{
+			MinLength: f.MinLength,
+			MaxLength: f.MaxLength,
+			Min:       f.Min,
+			Max:       f.Max,
+		},
+		Default:     f.Default,
+		Deprecation: parser.Deprecation{Deprecated: f.Deprecated, ReplacedBy: f.ReplacedBy},
+	}
+}
+
+func rawType(ref *parser.TypeRef) string {
+	if ref == nil {
+		return ""
+	}
+
+	return ref.RawType
+}
+
+func typeRef(raw string) *parser.TypeRef {
+	if raw == "" {
+		return nil
+	}
+
+	return parser.NewTypeRef(raw)
+}
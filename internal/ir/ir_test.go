@@ -0,0 +1,121 @@
+package ir_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/tgbotspec/internal/ir"
+	"github.com/metalagman/tgbotspec/internal/parser"
+)
+
+func TestRoundTrip(t *testing.T) {
+	maxLen := 4096
+	types := []parser.TypeDef{
+		{
+			Anchor:      "chatmember",
+			Name:        "ChatMember",
+			Tag:         "Available types",
+			Description: []string{"One of the members."},
+			OneOf:       []string{"ChatMemberOwner", "ChatMemberMember"},
+		},
+		{
+			Anchor: "user",
+			Name:   "User",
+			Notes:  []string{"A note."},
+			Fields: []parser.TypeFieldDef{{
+				Name:        "id",
+				TypeRef:     parser.NewTypeRef("Integer"),
+				Required:    true,
+				Description: "Identifier.",
+				Annotations: parser.Annotations{Int64: true},
+			}},
+		},
+	}
+	methods := []parser.MethodDef{
+		{
+			Anchor: "sendmessage",
+			Name:   "sendMessage",
+			Tags:   []string{"Available methods"},
+			Return: parser.NewTypeRef("Message"),
+			Params: []parser.MethodParamDef{{
+				Name:        "text",
+				TypeRef:     parser.NewTypeRef("String"),
+				Required:    true,
+				Description: "Text, 1-4096 characters.",
+				Annotations: parser.Annotations{
+					Enum:        []string{"a", "b"},
+					Constraints: parser.Constraints{MaxLength: &maxLen},
+					Default:     "a",
+					Deprecation: parser.Deprecation{Deprecated: true, ReplacedBy: "message"},
+				},
+			}},
+		},
+		{
+			Anchor:      "logout",
+			Name:        "logOut",
+			Deprecation: parser.Deprecation{Deprecated: true, ReplacedBy: "close"},
+		},
+	}
+
+	changelog := []parser.Release{{
+		Version: "7.2",
+		Date:    "2024-03-31",
+		Items: []parser.ChangeItem{{
+			Text:  "Added the method [logOut](#/paths/~1logOut/post).",
+			Refs:  []string{"logOut"},
+			Added: []string{"logOut"},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := ir.New("Telegram Bot API", "7.2", types, methods, changelog).WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON returned error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"version": 1,`) || !strings.Contains(buf.String(), `"max_length": 4096`) {
+		t.Fatalf("unexpected IR:\n%s", buf.String())
+	}
+
+	doc, err := ir.Read(&buf)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+
+	if doc.Title != "Telegram Bot API" || doc.APIVersion != "7.2" {
+		t.Fatalf("unexpected header %q %q", doc.Title, doc.APIVersion)
+	}
+
+	if got := doc.TypeDefs(); !reflect.DeepEqual(got, types) {
+		t.Errorf("types differ after round trip:\n%#v\nwant\n%#v", got, types)
+	}
+
+	if got := doc.MethodDefs(); !reflect.DeepEqual(got, methods) {
+		t.Errorf("methods differ after round trip:\n%#v\nwant\n%#v", got, methods)
+	}
+
+	if got := doc.Releases(); !reflect.DeepEqual(got, changelog) {
+		t.Errorf("changelog differs after round trip:\n%#v\nwant\n%#v", got, changelog)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	if _, err := ir.Read(strings.NewReader(`{"version": 2, "types": []}`)); !errors.Is(err, ir.ErrUnsupportedVersion) {
+		t.Errorf("expected ErrUnsupportedVersion, got %v", err)
+	}
+
+	if _, err := ir.Read(strings.NewReader(`{"title": "no version"}`)); !errors.Is(err, ir.ErrUnsupportedVersion) {
+		t.Errorf("expected ErrUnsupportedVersion for a missing version, got %v", err)
+	}
+
+	if _, err := ir.Read(strings.NewReader(`<html>`)); err == nil {
+		t.Error("expected error for non-JSON input")
+	}
+
+	doc, err := ir.Read(strings.NewReader(`{"version": 1, "future_field": true, "types": [{"name": "User"}]}`))
+	if err != nil || len(doc.Types) != 1 {
+		t.Errorf("expected unknown fields to be ignored, got %v", err)
+	}
+}
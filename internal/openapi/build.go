@@ -0,0 +1,261 @@
+package openapi
+
+import (
+	"maps"
+	"strings"
+)
+
+const (
+	securitySchemeName = "TelegramBotToken"
+	mediaTypeJSON      = "application/json"
+	mediaTypeMultipart = "multipart/form-data"
+)
+
+// BuildDocument builds the OpenAPI document described by data. It produces
+// the same document as the embedded template, but as a typed model; OpenAPI
+// 3.1 documents have their schemas converted with toOpenAPI31.
+func BuildDocument(data *TemplateData) *Document {
+	if data == nil {
+		return nil
+	}
+
+	version := data.OpenAPIVersion
+	if version == "" {
+		version = OpenAPIVersion30
+	}
+
+	doc := &Document{
+		OpenAPI: version,
+		Info: Info{
+			Title:   data.Title,
+			Version: data.Version,
+			Description: "This OpenAPI specification was generated using the " +
+				"[tgbotspec](https://github.com/metalagman/tgbotspec) tool.",
+		},
+		Servers:  []Server{telegramServer()},
+		Security: []SecurityRequirement{{securitySchemeName: {}}},
+	}
+
+	for i := range data.Methods {
+		doc.Paths.Set("/"+data.Methods[i].Name, &PathItem{Post: methodOperation(&data.Methods[i])})
+	}
+
+	if data.IsOpenAPI31() && data.HasType("Update") {
+		doc.Webhooks.Set("update", &PathItem{Post: updateWebhook(data)})
+	}
+
+	doc.Components.SecuritySchemes.Set(securitySchemeName, &SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: securitySchemeName,
+		Description:  "Telegram bot token included in the request URL segment (bot{token}).",
+	})
+
+	for i := range data.Types {
+		doc.Components.Schemas.Set(data.Types[i].Name, typeSchema(&data.Types[i]))
+	}
+
+	doc.Components.Schemas.Set("OkResponse", okResponseSchema())
+	doc.Components.Schemas.Set("ErrorResponse", errorResponseSchema())
+
+	if len(data.Changelog) > 0 {
+		doc.Extensions = map[string]interface{}{"x-changelog": data.ChangelogExtension()}
+	}
+
+	if data.IsOpenAPI31() {
+		doc.Schemas(toOpenAPI31)
+	}
+
+	return doc
+}
+
+func telegramServer() Server {
+	server := Server{
+		URL:         "https://api.telegram.org/bot{botToken}",
+		Description: "Telegram Bot API endpoint; substitute {botToken} with your bot token.",
+	}
+
+	server.Variables.Set("botToken", ServerVariable{
+		Description: "Telegram bot token obtained from BotFather.",
+		Default:     "<bot_token>",
+	})
+
+	return server
+}
+
+func methodOperation(m *Method) *Operation {
+	op := &Operation{
+		OperationID: m.Name,
+		Deprecated:  m.Deprecated,
+		Tags:        m.Tags,
+		Description: strings.Join(m.Description, "\n"),
+	}
+
+	if m.ReplacedBy != "" {
+		op.setExtension("x-replaced-by", m.ReplacedBy)
+	}
+
+	if m.Since != "" {
+		op.setExtension("x-since", m.Since)
+	}
+
+	if len(m.Params) > 0 {
+		op.RequestBody = &RequestBody{Required: true}
+		op.RequestBody.Content.Set(mediaTypeJSON, &MediaType{Schema: paramsSchema(m.Params, true)})
+
+		if m.SupportsMultipart {
+			op.RequestBody.Content.Set(mediaTypeMultipart, &MediaType{Schema: paramsSchema(m.Params, false)})
+		}
+	}
+
+	ok := &TypeSpec{Ref: &TypeRef{Name: "OkResponse"}}
+	if m.Return != nil {
+		ok = &TypeSpec{AllOf: []TypeSpec{
+			*ok,
+			{
+				Type:       "object",
+				Properties: map[string]TypeSpec{"result": *m.Return},
+				Required:   []string{"result"},
+			},
+		}}
+	}
+
+	op.Responses.Set("200", jsonResponse("OK", ok))
+	op.Responses.Set("400", jsonResponse("Bad Request", &TypeSpec{Ref: &TypeRef{Name: "ErrorResponse"}}))
+	op.Responses.Set("401", jsonResponse("Unauthorized", &TypeSpec{Ref: &TypeRef{Name: "ErrorResponse"}}))
+
+	return op
+}
+
+// paramsSchema builds the request body schema of a method. The JSON body
+// leaves out parameters that can only be uploaded as files.
+func paramsSchema(params []MethodParam, json bool) *TypeSpec {
+	spec := &TypeSpec{Type: "object", Properties: make(map[string]TypeSpec, len(params))}
+
+	for _, p := range params {
+		var schema *TypeSpec
+
+		if json {
+			if isPureBinary(p.Schema) {
+				continue
+			}
+
+			schema = simplifyJSON(p.Schema)
+		} else {
+			schema = simplifyMultipart(p.Schema)
+		}
+
+		if schema == nil {
+			schema = &TypeSpec{}
+		}
+
+		spec.Properties[p.Name] = *schema
+		spec.PropertyOrder = append(spec.PropertyOrder, p.Name)
+
+		if p.Required {
+			spec.Required = append(spec.Required, p.Name)
+		}
+	}
+
+	return spec
+}
+
+func jsonResponse(description string, schema *TypeSpec) *Response {
+	resp := &Response{Description: description}
+	resp.Content.Set(mediaTypeJSON, &MediaType{Schema: schema})
+
+	return resp
+}
+
+func updateWebhook(data *TemplateData) *Operation {
+	op := &Operation{
+		OperationID: "receiveUpdate",
+		Description: data.WebhookDescription(),
+		Security:    &[]SecurityRequirement{},
+		Parameters: []Parameter{{
+			Name:        "X-Telegram-Bot-Api-Secret-Token",
+			In:          "header",
+			Description: "The secret_token passed to setWebhook, if any.",
+			Schema:      &TypeSpec{Type: "string"},
+		}},
+		RequestBody: &RequestBody{Required: true},
+	}
+
+	op.RequestBody.Content.Set(mediaTypeJSON, &MediaType{Schema: &TypeSpec{Ref: &TypeRef{Name: "Update"}}})
+	op.Responses.Set("200", &Response{
+		Description: "The update was accepted; any other status makes Telegram retry the delivery.",
+	})
+
+	return op
+}
+
+func typeSchema(t *Type) *TypeSpec {
+	var spec TypeSpec
+
+	switch {
+	case t.Name == "True":
+		spec = TypeSpec{Type: "boolean", Enum: []interface{}{true}}
+	case t.Union != nil:
+		spec = *t.Union
+	default:
+		spec = TypeSpec{Type: "object"}
+
+		for _, f := range t.Fields {
+			if spec.Properties == nil {
+				spec.Properties = make(map[string]TypeSpec, len(t.Fields))
+			}
+
+			schema := TypeSpec{}
+			if f.Schema != nil {
+				schema = *f.Schema
+			}
+
+			spec.Properties[f.Name] = schema
+			spec.PropertyOrder = append(spec.PropertyOrder, f.Name)
+
+			if f.Required {
+				spec.Required = append(spec.Required, f.Name)
+			}
+		}
+	}
+
+	spec.Description = strings.Join(t.Description, "\n")
+
+	if t.Tag != "" || t.Since != "" {
+		spec.Extensions = maps.Clone(spec.Extensions)
+	}
+
+	if t.Tag != "" {
+		spec.SetExtension("x-tags", []string{t.Tag})
+	}
+
+	if t.Since != "" {
+		spec.SetExtension("x-since", t.Since)
+	}
+
+	return &spec
+}
+
+func okResponseSchema() *TypeSpec {
+	return &TypeSpec{
+		Type: "object",
+		Properties: map[string]TypeSpec{
+			"ok": {Type: "boolean", Enum: []interface{}{true}},
+		},
+		Required: []string{"ok"},
+	}
+}
+
+func errorResponseSchema() *TypeSpec {
+	return &TypeSpec{
+		Type: "object",
+		Properties: map[string]TypeSpec{
+			"ok":          {Type: "boolean", Enum: []interface{}{false}},
+			"error_code":  {Type: "integer"},
+			"description": {Type: "string"},
+			"parameters":  {Ref: &TypeRef{Name: "ResponseParameters"}},
+		},
+		PropertyOrder: []string{"ok", "error_code", "description", "parameters"},
+		Required:      []string{"ok", "error_code", "description"},
+	}
+}
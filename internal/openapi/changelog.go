@@ -0,0 +1,78 @@
+package openapi
+
+import "strings"
+
+// Release is a Bot API release of the documentation changelog.
+type Release struct {
+	// Version is the Bot API version, e.g. "7.2".
+	Version string
+	// Date is the release date as YYYY-MM-DD when known.
+	Date string
+	// Items hold the Markdown of each changelog bullet.
+	Items []ChangeItem
+}
+
+// ChangeItem is one bullet of a release.
+type ChangeItem struct {
+	Text string
+	// Refs names the types and methods the bullet links to.
+	Refs []string
+}
+
+// changelogEntry is a release as written to the x-changelog extension.
+type changelogEntry struct {
+	Version string            `yaml:"version"`
+	Date    string            `yaml:"date,omitempty"`
+	Changes []changelogChange `yaml:"changes,omitempty"`
+}
+
+type changelogChange struct {
+	Description string `yaml:"description"`
+	// Links point to the component schemas and operations of Refs.
+	Links []string `yaml:"links,omitempty"`
+}
+
+// ChangelogExtension returns the value of the x-changelog extension: the
+// releases with their changes, which link to the affected schemas and
+// operations. References to anything not emitted, such as section anchors
+// like "stickers", get no link.
+func (d *TemplateData) ChangelogExtension() interface{} {
+	res := make([]changelogEntry, 0, len(d.Changelog))
+
+	emitted := make(map[string]struct{}, len(d.Types)+len(d.Methods))
+	for _, t := range d.Types {
+		emitted[t.Name] = struct{}{}
+	}
+
+	for _, m := range d.Methods {
+		emitted[m.Name] = struct{}{}
+	}
+
+	for _, release := range d.Changelog {
+		entry := changelogEntry{Version: release.Version, Date: release.Date}
+
+		for _, item := range release.Items {
+			change := changelogChange{Description: item.Text}
+			for _, name := range item.Refs {
+				if _, ok := emitted[name]; ok {
+					change.Links = append(change.Links, refLink(name))
+				}
+			}
+
+			entry.Changes = append(entry.Changes, change)
+		}
+
+		res = append(res, entry)
+	}
+
+	return res
+}
+
+// refLink links a type to its component schema and a method to its operation.
+func refLink(name string) string {
+	if name != "" && strings.ToUpper(name[:1]) == name[:1] {
+		return schemaRef(name)
+	}
+
+	return "#/paths/~1" + name + "/post"
+}
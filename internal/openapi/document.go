@@ -0,0 +1,175 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a typed in-memory OpenAPI document. It covers the subset of the
+// specification the generated spec uses and is serialized with an encoder, so
+// it can be post-processed in Go before being written.
+type Document struct {
+	OpenAPI    string                 `yaml:"openapi"`
+	Info       Info                   `yaml:"info"`
+	Servers    []Server               `yaml:"servers,omitempty"`
+	Security   []SecurityRequirement  `yaml:"security,omitempty"`
+	Paths      OrderedMap[*PathItem]  `yaml:"paths"`
+	Webhooks   OrderedMap[*PathItem]  `yaml:"webhooks,omitempty"`
+	Components Components             `yaml:"components"`
+	Extensions map[string]interface{} `yaml:",inline"`
+}
+
+// Info is the metadata of the API.
+type Info struct {
+	Title       string `yaml:"title"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// Server is a server the API is served from; {placeholders} in URL are
+// described by Variables.
+type Server struct {
+	URL         string                     `yaml:"url"`
+	Description string                     `yaml:"description,omitempty"`
+	Variables   OrderedMap[ServerVariable] `yaml:"variables,omitempty"`
+}
+
+// ServerVariable describes a placeholder of a server URL.
+type ServerVariable struct {
+	Description string `yaml:"description,omitempty"`
+	Default     string `yaml:"default"`
+}
+
+// SecurityRequirement maps security scheme names to required scopes.
+type SecurityRequirement map[string][]string
+
+// PathItem holds the operations of a path. Every Bot API method is a POST.
+type PathItem struct {
+	Post *Operation `yaml:"post,omitempty"`
+}
+
+// Operation describes a single API operation.
+type Operation struct {
+	OperationID string   `yaml:"operationId,omitempty"`
+	Deprecated  bool     `yaml:"deprecated,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	// Security overrides the document security; an empty list disables it.
+	Security    *[]SecurityRequirement `yaml:"security,omitempty"`
+	Parameters  []Parameter            `yaml:"parameters,omitempty"`
+	RequestBody *RequestBody           `yaml:"requestBody,omitempty"`
+	Responses   OrderedMap[*Response]  `yaml:"responses"`
+	Extensions  map[string]interface{} `yaml:",inline"`
+}
+
+func (o *Operation) setExtension(name string, value interface{}) {
+	if o.Extensions == nil {
+		o.Extensions = make(map[string]interface{})
+	}
+
+	o.Extensions[name] = value
+}
+
+// Parameter is a header, query or path parameter of an operation.
+type Parameter struct {
+	Name        string    `yaml:"name"`
+	In          string    `yaml:"in"`
+	Required    bool      `yaml:"required"`
+	Description string    `yaml:"description,omitempty"`
+	Schema      *TypeSpec `yaml:"schema,omitempty"`
+}
+
+// RequestBody is the body of an operation keyed by media type.
+type RequestBody struct {
+	Required bool                   `yaml:"required"`
+	Content  OrderedMap[*MediaType] `yaml:"content"`
+}
+
+// MediaType holds the schema of a request or response body.
+type MediaType struct {
+	Schema *TypeSpec `yaml:"schema,omitempty"`
+}
+
+// Response is a response of an operation.
+type Response struct {
+	Description string                 `yaml:"description"`
+	Content     OrderedMap[*MediaType] `yaml:"content,omitempty"`
+}
+
+// Components holds the reusable parts of the document.
+type Components struct {
+	SecuritySchemes OrderedMap[*SecurityScheme] `yaml:"securitySchemes,omitempty"`
+	Schemas         OrderedMap[*TypeSpec]       `yaml:"schemas,omitempty"`
+}
+
+// SecurityScheme describes how requests are authorized.
+type SecurityScheme struct {
+	Type         string `yaml:"type"`
+	Scheme       string `yaml:"scheme,omitempty"`
+	BearerFormat string `yaml:"bearerFormat,omitempty"`
+	Description  string `yaml:"description,omitempty"`
+}
+
+// WriteYAML serializes the document as YAML.
+func (d *Document) WriteYAML(w io.Writer) error {
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(d); err != nil {
+		return fmt.Errorf("encode document: %w", err)
+	}
+
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("encode document: %w", err)
+	}
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+// Schemas calls fn for every schema of the document: component schemas and
+// the parameter, request and response schemas of paths and webhooks. fn may
+// replace the schema by returning a different one.
+func (d *Document) Schemas(fn func(*TypeSpec) *TypeSpec) {
+	for _, name := range d.Components.Schemas.Keys() {
+		schema, _ := d.Components.Schemas.Get(name)
+		d.Components.Schemas.Set(name, fn(schema))
+	}
+
+	for _, items := range []*OrderedMap[*PathItem]{&d.Paths, &d.Webhooks} {
+		for _, path := range items.Keys() {
+			item, _ := items.Get(path)
+			if item.Post != nil {
+				item.Post.schemas(fn)
+			}
+		}
+	}
+}
+
+func (o *Operation) schemas(fn func(*TypeSpec) *TypeSpec) {
+	for i := range o.Parameters {
+		o.Parameters[i].Schema = fn(o.Parameters[i].Schema)
+	}
+
+	if o.RequestBody != nil {
+		mediaSchemas(&o.RequestBody.Content, fn)
+	}
+
+	for _, code := range o.Responses.Keys() {
+		resp, _ := o.Responses.Get(code)
+		mediaSchemas(&resp.Content, fn)
+	}
+}
+
+func mediaSchemas(content *OrderedMap[*MediaType], fn func(*TypeSpec) *TypeSpec) {
+	for _, mediaType := range content.Keys() {
+		media, _ := content.Get(mediaType)
+		media.Schema = fn(media.Schema)
+	}
+}
@@ -0,0 +1,155 @@
+package openapi //nolint:testpackage // tests compare against the internal template renderer
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func documentFixture() *TemplateData {
+	user := &TypeSpec{Ref: &TypeRef{Name: "User"}}
+	photo := &TypeSpec{AnyOf: []TypeSpec{{Type: "string", Format: "binary"}, {Type: "string"}}}
+	photo.SetExtension("x-order", 1)
+
+	return &TemplateData{
+		Title:   "Telegram Bot API",
+		Version: "7.0",
+		Types: []Type{
+			{Name: "ChatMember", Description: []string{"One of the members."}, Tag: "Available types",
+				Union: &TypeSpec{OneOf: []TypeSpec{*user}}},
+			{Name: "ResponseParameters", Description: []string{"Why a request failed."}},
+			{Name: "Update", Description: []string{"An incoming update.", "Second line: with # and \"quotes\""},
+				Fields: []TypeField{
+					{Name: "update_id", Required: true, Schema: &TypeSpec{Type: "integer"}},
+					{Name: "from", Schema: user.WithDescription("Sender")},
+				}},
+			{Name: "User", Description: []string{"A user."}, Tag: "Available types", Since: "7.1", Fields: []TypeField{
+				{Name: "id", Required: true, Schema: &TypeSpec{Type: "integer", Format: "int64"}},
+				{Name: "kind", Schema: &TypeSpec{Type: "string", Enum: []interface{}{"bot"}, Nullable: true}},
+			}},
+		},
+		Changelog: []Release{
+			{Version: "7.10", Date: "2024-09-06", Items: []ChangeItem{
+				{Text: "Added the method [getMe](#/paths/~1getMe/post): returns a *User*.", Refs: []string{"getMe", "User"}},
+				{Text: "Supported new things."},
+			}},
+			{Version: "7.1"},
+		},
+		Methods: []Method{
+			{Name: "getMe", Description: []string{"Returns the bot."}, Return: user, Since: "7.10"},
+			{Name: "logOut", Description: []string{"Logs out."}, Deprecated: true, ReplacedBy: "close"},
+			{
+				Name:              "sendPhoto",
+				Tags:              []string{"Available methods"},
+				Description:       []string{"Sends a photo: |- and more."},
+				SupportsMultipart: true,
+				Return:            &TypeSpec{Type: "array", Items: user},
+				Params: []MethodParam{
+					{Name: "file", Required: true, Schema: &TypeSpec{Type: "string", Format: "binary"}},
+					{Name: "photo", Required: true, Schema: photo},
+					{Name: "caption", Schema: &TypeSpec{Type: "string"}},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildDocumentMatchesTemplate(t *testing.T) {
+	for _, version := range []string{OpenAPIVersion30, OpenAPIVersion31} {
+		t.Run(version, func(t *testing.T) {
+			data := documentFixture()
+			data.OpenAPIVersion = version
+
+			var rendered, built bytes.Buffer
+			if err := RenderTemplate(&rendered, data); err != nil {
+				t.Fatalf("render template: %v", err)
+			}
+
+			if err := BuildDocument(data).WriteYAML(&built); err != nil {
+				t.Fatalf("write document: %v", err)
+			}
+
+			var want, got interface{}
+			if err := yaml.Unmarshal(rendered.Bytes(), &want); err != nil {
+				t.Fatalf("parse template output: %v", err)
+			}
+
+			if err := yaml.Unmarshal(built.Bytes(), &got); err != nil {
+				t.Fatalf("parse document output: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("document differs from template output:\n%s\nwant\n%s", built.String(), rendered.String())
+			}
+		})
+	}
+}
+
+func TestBuildDocumentOrder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := BuildDocument(documentFixture()).WriteYAML(&buf); err != nil {
+		t.Fatalf("write document: %v", err)
+	}
+
+	out := buf.String()
+
+	for _, names := range [][2]string{
+		{"/getMe:", "/sendPhoto:"},
+		{"    User:", "    OkResponse:"},
+		{"update_id:", "from:"},
+		{"photo:", "caption:"},
+		{"ok:", "error_code:"},
+	} {
+		if i, j := strings.Index(out, names[0]), strings.Index(out, names[1]); i < 0 || j < 0 || i > j {
+			t.Errorf("expected %q before %q", names[0], names[1])
+		}
+	}
+
+	data := documentFixture()
+	data.Types = append(data.Types, Type{Name: "True", Description: []string{"Always true."}})
+	buf.Reset()
+
+	if err := BuildDocument(data).WriteYAML(&buf); err != nil {
+		t.Fatalf("write document: %v", err)
+	}
+
+	// A plain True key would be read back as a boolean.
+	if !strings.Contains(buf.String(), "    \"True\":\n      type: boolean\n") {
+		t.Errorf("expected quoted True schema in\n%s", buf.String())
+	}
+
+	if BuildDocument(nil) != nil {
+		t.Fatal("expected nil document for nil data")
+	}
+}
+
+func TestOrderedMap(t *testing.T) {
+	var m OrderedMap[int]
+
+	if !m.IsZero() {
+		t.Fatal("expected new map to be empty")
+	}
+
+	m.Set("b", 1)
+	m.Set("a", 2)
+	m.Set("c", 3)
+	m.Set("b", 4)
+	m.Delete("a")
+	m.Delete("missing")
+
+	if v, ok := m.Get("b"); !ok || v != 4 || m.Len() != 2 {
+		t.Fatalf("unexpected map state %v", m.Keys())
+	}
+
+	out, err := yaml.Marshal(m)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if string(out) != "b: 4\nc: 3\n" {
+		t.Fatalf("unexpected yaml %q", out)
+	}
+}
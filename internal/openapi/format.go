@@ -0,0 +1,122 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of the generated spec.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// YAMLToJSON converts a YAML document into indented JSON. Unlike a round trip
+// through map[string]interface{}, mapping keys keep their order, so paths,
+// schemas and properties appear in the same order as in the YAML document.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse yaml: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, &doc); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, fmt.Errorf("indent json: %w", err)
+	}
+
+	out.WriteByte('\n')
+
+	return out.Bytes(), nil
+}
+
+//nolint:cyclop // one branch per node kind
+func writeJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+
+			return nil
+		}
+
+		return writeJSON(buf, node.Content[0])
+	case yaml.AliasNode:
+		return writeJSON(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			writeJSONString(buf, node.Content[i].Value)
+			buf.WriteByte(':')
+
+			if err := writeJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := writeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		return writeJSONScalar(buf, node)
+	default:
+		return fmt.Errorf("unsupported yaml node kind %d at line %d", node.Kind, node.Line)
+	}
+
+	return nil
+}
+
+func writeJSONScalar(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.ShortTag() {
+	case "!!null":
+		buf.WriteString("null")
+	case "!!bool", "!!int", "!!float":
+		var v interface{}
+		if err := node.Decode(&v); err != nil {
+			return fmt.Errorf("decode %s at line %d: %w", node.ShortTag(), node.Line, err)
+		}
+
+		out, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("encode %s at line %d: %w", strconv.Quote(node.Value), node.Line, err)
+		}
+
+		buf.Write(out)
+	default:
+		writeJSONString(buf, node.Value)
+	}
+
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	out, _ := json.Marshal(s) //nolint:errchkjson // strings always marshal
+
+	buf.Write(out)
+}
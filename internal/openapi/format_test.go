@@ -0,0 +1,100 @@
+package openapi //nolint:testpackage // tests share fixtures with the renderer tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	in := `openapi: 3.0.0
+paths:
+  /sendMessage: {}
+  /getMe: {}
+components:
+  schemas:
+    Zeta:
+      type: [string, "null"]
+      enum: [true, 1, 2.5, null, "null"]
+    "True":
+      type: boolean
+    Alpha:
+      default: &d "x"
+      example: *d
+responses:
+  '200':
+    description: OK
+`
+
+	out, err := YAMLToJSON([]byte(in))
+	if err != nil {
+		t.Fatalf("YAMLToJSON: %v", err)
+	}
+
+	got := string(out)
+	if strings.Index(got, `"/sendMessage"`) > strings.Index(got, `"/getMe"`) ||
+		strings.Index(got, `"Zeta"`) > strings.Index(got, `"Alpha"`) {
+		t.Fatalf("expected key order to be kept, got\n%s", got)
+	}
+
+	compact := strings.Join(strings.Fields(got), "")
+	for _, want := range []string{
+		`"openapi":"3.0.0"`,
+		`"type":["string","null"]`,
+		`"enum":[true,1,2.5,null,"null"]`,
+		`"example":"x"`,
+		`"200":{`,
+		`"True":{`,
+	} {
+		if !strings.Contains(compact, want) {
+			t.Errorf("expected %s in\n%s", want, got)
+		}
+	}
+
+	if _, err := YAMLToJSON([]byte("a: [")); err == nil {
+		t.Fatal("expected error for invalid yaml")
+	}
+}
+
+func TestYAMLToJSONMatchesRenderedSpec(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderTemplate(&buf, &TemplateData{
+		Title:   "Telegram Bot API",
+		Version: "7.0",
+		Types: []Type{
+			{Name: "User", Fields: []TypeField{{Name: "id", Required: true, Schema: &TypeSpec{Type: "integer"}}}},
+		},
+		Methods: []Method{
+			{Name: "getMe", Description: []string{"Returns the bot."}, Return: &TypeSpec{Ref: &TypeRef{Name: "User"}}},
+		},
+	}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	out, err := YAMLToJSON(buf.Bytes())
+	if err != nil {
+		t.Fatalf("YAMLToJSON: %v", err)
+	}
+
+	want, err := yaml.YAMLToJSON(buf.Bytes())
+	if err != nil {
+		t.Fatalf("reference conversion: %v", err)
+	}
+
+	var gotDoc, wantDoc interface{}
+	if err := json.Unmarshal(out, &gotDoc); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+
+	if err := json.Unmarshal(want, &wantDoc); err != nil {
+		t.Fatalf("unmarshal reference: %v", err)
+	}
+
+	if !reflect.DeepEqual(gotDoc, wantDoc) {
+		t.Fatalf("JSON output differs from the YAML document:\n%s", out)
+	}
+}
@@ -0,0 +1,113 @@
+package openapi
+
+import "text/template"
+
+// OpenAPI versions the template can emit.
+const (
+	OpenAPIVersion30 = "3.0.0"
+	OpenAPIVersion31 = "3.1.0"
+)
+
+// openAPI31Funcs replace the schema renderers of the template when emitting
+// OpenAPI 3.1, converting every schema with toOpenAPI31 after simplification.
+var openAPI31Funcs = template.FuncMap{
+	"renderSchema": func(spec *TypeSpec) (string, error) {
+		return renderSchema(toOpenAPI31(spec))
+	},
+	"renderJSONSchema": func(spec *TypeSpec) (string, error) {
+		return RenderTypeSpecToYAML(toOpenAPI31(simplifyJSON(spec)))
+	},
+	"renderMultipartSchema": func(spec *TypeSpec) (string, error) {
+		return RenderTypeSpecToYAML(toOpenAPI31(simplifyMultipart(spec)))
+	},
+}
+
+// toOpenAPI31 returns a copy of spec rewritten with the keywords OpenAPI 3.1
+// (JSON Schema 2020-12) offers natively in place of the 3.0 workarounds:
+//
+//   - allOf wrapping a single $ref (see WithDescription) becomes a plain $ref
+//     with sibling keywords,
+//   - nullable becomes a type list with "null",
+//   - single-value enums, used for discriminator values, become const,
+//   - example becomes examples.
+//
+//nolint:cyclop // one branch per rewritten keyword
+func toOpenAPI31(spec *TypeSpec) *TypeSpec {
+	if spec == nil {
+		return nil
+	}
+
+	res := *spec
+	res.Items = toOpenAPI31(spec.Items)
+	res.OneOf = toOpenAPI31List(spec.OneOf)
+	res.AnyOf = toOpenAPI31List(spec.AnyOf)
+	res.AllOf = toOpenAPI31List(spec.AllOf)
+
+	if spec.Properties != nil {
+		res.Properties = make(map[string]TypeSpec, len(spec.Properties))
+		for name, prop := range spec.Properties {
+			res.Properties[name] = *toOpenAPI31(&prop)
+		}
+	}
+
+	switch ap := spec.AdditionalProperties.(type) {
+	case *TypeSpec:
+		res.AdditionalProperties = toOpenAPI31(ap)
+	case TypeSpec:
+		res.AdditionalProperties = toOpenAPI31(&ap)
+	}
+
+	if len(res.AllOf) == 1 && isRefOnly(&res.AllOf[0]) && res.Ref == nil && res.Type == "" &&
+		len(res.Properties) == 0 {
+		res.Ref = res.AllOf[0].Ref
+		res.AllOf = nil
+	}
+
+	if len(res.Enum) == 1 {
+		res.Const = res.Enum[0]
+		res.Enum = nil
+	}
+
+	if res.Example != "" {
+		res.Examples = []interface{}{res.Example}
+		res.Example = ""
+	}
+
+	if res.Nullable {
+		res.Nullable = false
+
+		if res.Type == "" {
+			desc, ext := res.Description, res.Extensions
+			res.Description, res.Extensions = "", nil
+
+			return &TypeSpec{
+				AnyOf:       []TypeSpec{res, {Type: "null"}},
+				Description: desc,
+				Extensions:  ext,
+			}
+		}
+
+		res.Types = []string{res.Type, "null"}
+		res.Type = ""
+	}
+
+	return &res
+}
+
+func toOpenAPI31List(specs []TypeSpec) []TypeSpec {
+	if specs == nil {
+		return nil
+	}
+
+	res := make([]TypeSpec, 0, len(specs))
+	for i := range specs {
+		res = append(res, *toOpenAPI31(&specs[i]))
+	}
+
+	return res
+}
+
+func isRefOnly(spec *TypeSpec) bool {
+	return spec.Ref != nil && spec.Description == "" && spec.Type == "" && len(spec.Extensions) == 0 &&
+		len(spec.OneOf)+len(spec.AnyOf)+len(spec.AllOf) == 0
+}
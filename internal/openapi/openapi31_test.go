@@ -0,0 +1,114 @@
+package openapi //nolint:testpackage // access internal helpers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestToOpenAPI31(t *testing.T) {
+	ref := &TypeSpec{Ref: &TypeRef{Name: "User"}}
+	spec := &TypeSpec{
+		Type: "object",
+		Properties: map[string]TypeSpec{
+			"from":  *ref.WithDescription("Sender"),
+			"kind":  {Type: "string", Enum: []interface{}{"creator"}},
+			"title": {Type: "string", Nullable: true, Example: "Chat"},
+			"reply": {Ref: &TypeRef{Name: "Message"}, Nullable: true, Description: "Reply"},
+		},
+	}
+
+	out, err := RenderTypeSpecToYAML(toOpenAPI31(spec))
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	for _, want := range []string{
+		"from:\n        description: Sender\n        $ref: '#/components/schemas/User'",
+		"kind:\n        type: string\n        const: creator",
+		"title:\n        type: [string, \"null\"]\n        examples:\n            - Chat",
+		"reply:\n        description: Reply\n        anyOf:\n            - $ref: '#/components/schemas/Message'\n            - type: \"null\"",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in\n%s", want, out)
+		}
+	}
+
+	if len(spec.Properties["from"].AllOf) != 1 || spec.Properties["kind"].Enum == nil {
+		t.Fatal("expected the original spec to be left untouched")
+	}
+}
+
+func TestRenderTemplateOpenAPI31(t *testing.T) {
+	data := &TemplateData{
+		Title:          "Telegram Bot API",
+		Version:        "7.0",
+		OpenAPIVersion: OpenAPIVersion31,
+		Types: []Type{
+			{Name: "ResponseParameters"},
+			{Name: "Update", Fields: []TypeField{
+				{Name: "message", Schema: (&TypeSpec{Ref: &TypeRef{Name: "Update"}}).WithDescription("New message")},
+			}},
+		},
+		Methods: []Method{{Name: "getUpdates", Return: &TypeSpec{Type: "array", Items: &TypeSpec{Ref: &TypeRef{Name: "Update"}}}}},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderTemplate(&buf, data); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"openapi: 3.1.0\n",
+		"webhooks:\n  update:\n",
+		"          const: true\n",
+		"description: New message\n          $ref: '#/components/schemas/Update'",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output", want)
+		}
+	}
+
+	if err := Validate(t.Context(), buf.Bytes()); err != nil {
+		t.Fatalf("expected rendered OpenAPI 3.1 spec to be valid, got %v", err)
+	}
+
+	if !strings.Contains(out, "URL set with setWebhook.\n") || strings.Contains(out, "~1setWebhook") {
+		t.Errorf("expected no link to the missing setWebhook operation in\n%s", out)
+	}
+
+	withSetWebhook := *data
+	withSetWebhook.Methods = append([]Method{{Name: "setWebhook", Return: &TypeSpec{Type: "boolean"}}}, data.Methods...)
+
+	for name, render := range map[string]func(*bytes.Buffer) error{
+		"template": func(w *bytes.Buffer) error { return RenderTemplate(w, &withSetWebhook) },
+		"document": func(w *bytes.Buffer) error { return BuildDocument(&withSetWebhook).WriteYAML(w) },
+	} {
+		var linked bytes.Buffer
+		if err := render(&linked); err != nil {
+			t.Fatalf("%s: render: %v", name, err)
+		}
+
+		if !strings.Contains(linked.String(), "URL set with [setWebhook](#/paths/~1setWebhook/post).\n") {
+			t.Errorf("%s: expected a link to the setWebhook operation", name)
+		}
+	}
+
+	data.OpenAPIVersion = ""
+	buf.Reset()
+
+	if err := RenderTemplate(&buf, data); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	out = buf.String()
+	if !strings.HasPrefix(out, "openapi: 3.0.0\n") || strings.Contains(out, "webhooks:") ||
+		strings.Contains(out, "const:") {
+		t.Fatalf("expected plain OpenAPI 3.0 output, got\n%s", out)
+	}
+
+	if err := Validate(t.Context(), buf.Bytes()); err != nil {
+		t.Fatalf("expected valid 3.0 output, got %v", err)
+	}
+}
@@ -133,6 +133,14 @@ func TestRenderTypeSpecToYAML(t *testing.T) { //nolint:funlen // table-driven ca
 			},
 			expectedYAML: "anyOf:\n- type: string\n- type: integer\n",
 		},
+		{
+			name: "Extensions",
+			spec: TypeSpec{
+				Type:       "string",
+				Extensions: map[string]interface{}{"x-order": 3},
+			},
+			expectedYAML: "type: string\nx-order: 3\n",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,79 @@
+package openapi
+
+import "gopkg.in/yaml.v3"
+
+// OrderedMap is a string-keyed mapping that is serialized in insertion order,
+// so paths and schemas keep the order they were added in instead of the
+// alphabetical order yaml.v3 uses for Go maps.
+type OrderedMap[V any] struct {
+	keys   []string
+	values map[string]V
+}
+
+// Set adds or replaces the value for key; new keys are appended.
+func (m *OrderedMap[V]) Set(key string, value V) {
+	if m.values == nil {
+		m.values = make(map[string]V)
+	}
+
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+
+	m.values[key] = value
+}
+
+// Get returns the value for key and whether it is present.
+func (m *OrderedMap[V]) Get(key string) (V, bool) {
+	v, ok := m.values[key]
+
+	return v, ok
+}
+
+// Delete removes key, keeping the order of the remaining keys.
+func (m *OrderedMap[V]) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+
+	delete(m.values, key)
+
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+
+			break
+		}
+	}
+}
+
+// Keys returns the keys in insertion order.
+func (m *OrderedMap[V]) Keys() []string {
+	return m.keys
+}
+
+// Len returns the number of entries.
+func (m *OrderedMap[V]) Len() int {
+	return len(m.keys)
+}
+
+// IsZero reports an empty map, letting omitempty drop it.
+func (m OrderedMap[V]) IsZero() bool {
+	return len(m.keys) == 0
+}
+
+// MarshalYAML encodes the entries as a mapping in insertion order.
+func (m OrderedMap[V]) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	for _, key := range m.keys {
+		var value yaml.Node
+		if err := value.Encode(m.values[key]); err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &value)
+	}
+
+	return node, nil
+}
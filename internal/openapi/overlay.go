@@ -0,0 +1,107 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/speakeasy-api/openapi-overlay/pkg/overlay"
+	"gopkg.in/yaml.v3"
+)
+
+// Overlay is an OpenAPI Overlay 1.0 document: actions that update or remove
+// the parts of a spec selected by JSONPath targets.
+type Overlay struct {
+	// Name identifies the overlay in errors and unmatched target reports.
+	Name string
+	doc  overlay.Overlay
+}
+
+// LoadOverlay reads and validates an overlay file.
+func LoadOverlay(path string) (*Overlay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read overlay: %w", err)
+	}
+
+	return ParseOverlay(path, data)
+}
+
+// ParseOverlay decodes and validates the overlay named name.
+func ParseOverlay(name string, data []byte) (*Overlay, error) {
+	o := &Overlay{Name: name}
+
+	if err := yaml.Unmarshal(data, &o.doc); err != nil {
+		return nil, fmt.Errorf("decode overlay %s: %w", name, err)
+	}
+
+	if err := o.doc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid overlay %s: %w", name, err)
+	}
+
+	for _, action := range o.doc.Actions {
+		if _, err := o.doc.NewPath(action.Target, nil); err != nil {
+			return nil, fmt.Errorf("invalid overlay %s: target %s: %w", name, action.Target, err)
+		}
+	}
+
+	return o, nil
+}
+
+// OverlayIssue describes an overlay action whose target matched nothing,
+// which usually means the overlay went stale after the docs changed.
+type OverlayIssue struct {
+	Overlay string
+	Target  string
+}
+
+// ApplyOverlays applies the overlays in order to a rendered YAML spec and
+// returns the updated spec with the actions whose target matched nothing.
+func ApplyOverlays(spec []byte, overlays []*Overlay) ([]byte, []OverlayIssue, error) {
+	if len(overlays) == 0 {
+		return spec, nil, nil
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(spec, &root); err != nil {
+		return nil, nil, fmt.Errorf("decode spec: %w", err)
+	}
+
+	var issues []OverlayIssue
+
+	for _, o := range overlays {
+		for _, action := range o.doc.Actions {
+			path, err := o.doc.NewPath(action.Target, nil)
+			if err != nil {
+				return nil, nil, fmt.Errorf("overlay %s: target %s: %w", o.Name, action.Target, err)
+			}
+
+			if len(path.Query(&root)) == 0 {
+				issues = append(issues, OverlayIssue{Overlay: o.Name, Target: action.Target})
+
+				continue
+			}
+
+			// Apply one action at a time, so a target added by an earlier
+			// action is matched by a later one.
+			step := o.doc
+			step.Actions = []overlay.Action{action}
+
+			if err := step.ApplyTo(&root); err != nil {
+				return nil, nil, fmt.Errorf("apply overlay %s: target %s: %w", o.Name, action.Target, err)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := errors.Join(enc.Encode(&root), enc.Close()); err != nil {
+		return nil, nil, fmt.Errorf("encode spec: %w", err)
+	}
+
+	return buf.Bytes(), issues, nil
+}
@@ -0,0 +1,138 @@
+package openapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/tgbotspec/internal/openapi"
+)
+
+const overlaySpec = `openapi: 3.0.0
+info:
+  title: Telegram Bot API
+  version: "7.0"
+servers:
+  - url: https://api.telegram.org/bot{botToken}
+paths:
+  /getMe:
+    post:
+      description: A simple method for testing your bot's authentication token.
+  /logOut:
+    post:
+      description: Log out from the cloud Bot API server.
+`
+
+func mustOverlay(t *testing.T, name, data string) *openapi.Overlay {
+	t.Helper()
+
+	o, err := openapi.ParseOverlay(name, []byte(data))
+	if err != nil {
+		t.Fatalf("ParseOverlay returned error: %v", err)
+	}
+
+	return o
+}
+
+func TestApplyOverlays(t *testing.T) {
+	servers := mustOverlay(t, "servers.yaml", `overlay: 1.0.0
+info:
+  title: Local server
+  version: 1.0.0
+actions:
+  - target: $.info
+    update:
+      x-logo: logo.png
+  - target: $.servers
+    remove: true
+  - target: $
+    update:
+      servers:
+        - url: http://localhost:8081/bot{botToken}
+`)
+	paths := mustOverlay(t, "paths.yaml", `overlay: 1.0.0
+info:
+  title: Paths
+  version: 1.0.0
+actions:
+  - target: $.paths['/getMe'].post
+    update:
+      description: Checks the token.
+      x-internal: false
+  - target: $.paths['/logOut']
+    remove: true
+  - target: $.paths['/sendMessage'].post
+    update:
+      x-internal: true
+`)
+
+	out, issues, err := openapi.ApplyOverlays([]byte(overlaySpec), []*openapi.Overlay{servers, paths})
+	if err != nil {
+		t.Fatalf("ApplyOverlays returned error: %v", err)
+	}
+
+	spec := string(out)
+	for _, want := range []string{
+		"  x-logo: logo.png\n",
+		"servers:\n  - url: http://localhost:8081/bot{botToken}\n",
+		"      description: Checks the token.\n      x-internal: false\n",
+	} {
+		if !strings.Contains(spec, want) {
+			t.Errorf("expected %q in:\n%s", want, spec)
+		}
+	}
+
+	for _, unwanted := range []string{"api.telegram.org", "/logOut"} {
+		if strings.Contains(spec, unwanted) {
+			t.Errorf("unexpected %q in:\n%s", unwanted, spec)
+		}
+	}
+
+	want := []openapi.OverlayIssue{{Overlay: "paths.yaml", Target: "$.paths['/sendMessage'].post"}}
+	if !reflect.DeepEqual(issues, want) {
+		t.Fatalf("expected unmatched target %+v, got %+v", want, issues)
+	}
+
+	if out, issues, err := openapi.ApplyOverlays([]byte(overlaySpec), nil); err != nil || string(out) != overlaySpec ||
+		issues != nil {
+		t.Fatalf("expected the spec unchanged without overlays, got %q %+v %v", out, issues, err)
+	}
+}
+
+func TestParseOverlayErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"invalid yaml": "overlay: [",
+		"no version":   "info: {title: T, version: 1.0.0}\nactions:\n  - target: $.info\n    remove: true\n",
+		"no actions":   "overlay: 1.0.0\ninfo: {title: T, version: 1.0.0}\n",
+		"invalid target": "overlay: 1.0.0\ninfo: {title: T, version: 1.0.0}\nx-speakeasy-jsonpath: rfc9535\n" +
+			"actions:\n  - target: $[\n    remove: true\n",
+	} {
+		if _, err := openapi.ParseOverlay("test.yaml", []byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := openapi.LoadOverlay(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for a missing overlay file")
+	}
+}
+
+func TestLoadOverlay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overlay.yaml")
+
+	data := "overlay: 1.0.0\ninfo: {title: T, version: 1.0.0}\nactions:\n  - target: $.info\n    update: {x-a: 1}\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write overlay: %v", err)
+	}
+
+	o, err := openapi.LoadOverlay(path)
+	if err != nil {
+		t.Fatalf("LoadOverlay returned error: %v", err)
+	}
+
+	if o.Name != path {
+		t.Fatalf("expected overlay named %q, got %q", path, o.Name)
+	}
+}
@@ -0,0 +1,161 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// templateSchemas are the component schemas defined by the template itself.
+var templateSchemas = []string{"OkResponse", "ErrorResponse"}
+
+// scalarAliases maps names that are not schemas but scalar spellings, e.g.
+// from a misparsed phrase, to the inline schema they stand for.
+var scalarAliases = map[string]TypeSpec{
+	"int":     {Type: "integer"},
+	"int32":   {Type: "integer", Format: "int32"},
+	"int64":   {Type: "integer", Format: "int64"},
+	"integer": {Type: "integer"},
+	"long":    {Type: "integer", Format: "int64"},
+	"float":   {Type: "number"},
+	"double":  {Type: "number"},
+	"number":  {Type: "number"},
+	"string":  {Type: "string"},
+	"str":     {Type: "string"},
+	"boolean": {Type: "boolean"},
+	"bool":    {Type: "boolean"},
+}
+
+// RefIssue describes a $ref that did not match a component schema.
+type RefIssue struct {
+	// Location is the "Type.field" or "method.param" path of the reference,
+	// or the type or method name for unions and return types.
+	Location string
+	Name     string
+	// Resolution is the schema or scalar type the reference was rewritten
+	// to, or empty when it could not be resolved and still dangles.
+	Resolution string
+}
+
+// ResolveRefs checks every $ref in data against the emitted component
+// schemas. References that differ only by plural, casing or a scalar alias
+// ("Messages", "message", "Int") are rewritten in place; every mismatch is
+// returned so callers can report it.
+func ResolveRefs(data *TemplateData) []RefIssue {
+	if data == nil {
+		return nil
+	}
+
+	r := refResolver{schemas: make(map[string]string, len(data.Types)+len(templateSchemas))}
+
+	for _, t := range data.Types {
+		r.schemas[strings.ToLower(t.Name)] = t.Name
+	}
+
+	for _, name := range templateSchemas {
+		r.schemas[strings.ToLower(name)] = name
+	}
+
+	for i := range data.Types {
+		t := &data.Types[i]
+		r.resolve(t.Name, t.Union)
+
+		for j := range t.Fields {
+			r.resolve(t.Name+"."+t.Fields[j].Name, t.Fields[j].Schema)
+		}
+	}
+
+	for i := range data.Methods {
+		m := &data.Methods[i]
+		r.resolve(m.Name, m.Return)
+
+		for j := range m.Params {
+			r.resolve(m.Name+"."+m.Params[j].Name, m.Params[j].Schema)
+		}
+	}
+
+	return r.issues
+}
+
+type refResolver struct {
+	// schemas maps lower-cased schema names to their spelling.
+	schemas map[string]string
+	issues  []RefIssue
+}
+
+func (r *refResolver) resolve(location string, spec *TypeSpec) {
+	if spec == nil {
+		return
+	}
+
+	if spec.Ref != nil && spec.Ref.Name != "" {
+		r.resolveRef(location, spec)
+	}
+
+	for _, list := range [][]TypeSpec{spec.OneOf, spec.AnyOf, spec.AllOf} {
+		for i := range list {
+			r.resolve(location, &list[i])
+		}
+	}
+
+	names := make([]string, 0, len(spec.Properties))
+	for name := range spec.Properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := spec.Properties[name]
+		r.resolve(location+"."+name, &prop)
+		spec.Properties[name] = prop
+	}
+
+	r.resolve(location, spec.Items)
+}
+
+func (r *refResolver) resolveRef(location string, spec *TypeSpec) {
+	name := spec.Ref.Name
+	if schema, ok := r.schemas[strings.ToLower(name)]; ok && schema == name {
+		return
+	}
+
+	issue := RefIssue{Location: location, Name: name}
+
+	for _, candidate := range refCandidates(name) {
+		key := strings.ToLower(candidate)
+
+		if schema, ok := r.schemas[key]; ok {
+			spec.Ref = &TypeRef{Name: schema}
+			issue.Resolution = schema
+
+			break
+		}
+
+		if alias, ok := scalarAliases[key]; ok {
+			spec.Ref = nil
+			spec.Type = alias.Type
+			spec.Format = alias.Format
+			issue.Resolution = alias.Type
+
+			break
+		}
+	}
+
+	r.issues = append(r.issues, issue)
+}
+
+// refCandidates returns the name followed by its likely singular forms.
+func refCandidates(name string) []string {
+	candidates := []string{name}
+
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		candidates = append(candidates, strings.TrimSuffix(name, "ies")+"y")
+	case strings.HasSuffix(name, "es"):
+		candidates = append(candidates, strings.TrimSuffix(name, "es"), strings.TrimSuffix(name, "s"))
+	case strings.HasSuffix(name, "s"):
+		candidates = append(candidates, strings.TrimSuffix(name, "s"))
+	}
+
+	return candidates
+}
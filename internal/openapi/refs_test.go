@@ -0,0 +1,63 @@
+package openapi //nolint:testpackage // tests construct internal types directly
+
+import (
+	"reflect"
+	"testing"
+)
+
+func ref(name string) TypeSpec {
+	return TypeSpec{Ref: &TypeRef{Name: name}}
+}
+
+func TestResolveRefs(t *testing.T) { //nolint:funlen // one fixture exercises every fix
+	stories := ref("Stories")
+	data := &TemplateData{
+		Types: []Type{
+			{Name: "Message", Fields: []TypeField{
+				{Name: "reply", Schema: &TypeSpec{AllOf: []TypeSpec{ref("Message")}}},
+				{Name: "entities", Schema: &TypeSpec{Type: "array", Items: &TypeSpec{OneOf: []TypeSpec{ref("Messages"), ref("Int")}}}},
+				{Name: "story", Schema: &stories},
+			}},
+			{Name: "Story"},
+			{Name: "Chat", Union: &TypeSpec{OneOf: []TypeSpec{ref("message"), ref("ChatUnknown")}}},
+		},
+		Methods: []Method{
+			{Name: "getMe", Return: &TypeSpec{Properties: map[string]TypeSpec{"ok": ref("OkResponse"), "x": ref("Strings")}}},
+		},
+	}
+
+	issues := ResolveRefs(data)
+
+	want := []RefIssue{
+		{Location: "Message.entities", Name: "Messages", Resolution: "Message"},
+		{Location: "Message.entities", Name: "Int", Resolution: "integer"},
+		{Location: "Message.story", Name: "Stories", Resolution: "Story"},
+		{Location: "Chat", Name: "message", Resolution: "Message"},
+		{Location: "Chat", Name: "ChatUnknown"},
+		{Location: "getMe.x", Name: "Strings", Resolution: "string"},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Fatalf("unexpected issues:\n%#v\nwant\n%#v", issues, want)
+	}
+
+	items := data.Types[0].Fields[1].Schema.Items.OneOf
+	if items[0].Ref.Name != "Message" || items[1].Ref != nil || items[1].Type != "integer" {
+		t.Fatalf("expected refs to be rewritten, got %#v", items)
+	}
+
+	if data.Types[0].Fields[2].Schema.Ref.Name != "Story" {
+		t.Fatalf("expected plural to be fixed, got %#v", data.Types[0].Fields[2].Schema)
+	}
+
+	if x := data.Methods[0].Return.Properties["x"]; x.Type != "string" || x.Ref != nil {
+		t.Fatalf("expected property ref to become a string, got %#v", x)
+	}
+
+	if data.Types[2].Union.OneOf[1].Ref.Name != "ChatUnknown" {
+		t.Fatal("expected unresolved ref to be left alone")
+	}
+
+	if ResolveRefs(nil) != nil {
+		t.Fatal("expected no issues for nil data")
+	}
+}
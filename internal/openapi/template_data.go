@@ -3,22 +3,86 @@ package openapi
 // TemplateData carries the information required to render the OpenAPI
 // specification template. It intentionally avoids dependencies on the parser
 // package so the renderer can live entirely within the openapi package.
+//
+// TemplateData is the contract for user-supplied templates (--template) and
+// is kept stable: fields and methods are only added, never renamed or
+// removed. Methods are sorted by name and Types by name; every schema
+// reference in them resolves to a Type or to one of the template schemas
+// OkResponse and ErrorResponse.
 type TemplateData struct {
-	Title   string
+	// Title is the title of the documentation page, e.g. "Telegram Bot API".
+	Title string
+	// Version is the Bot API version, e.g. "7.2", or "0.0.0" when unknown.
 	Version string
-	Methods []Method
-	Types   []Type
+	// OpenAPIVersion selects the emitted dialect, OpenAPIVersion30 (the
+	// default when empty) or OpenAPIVersion31.
+	OpenAPIVersion string
+	Methods        []Method
+	Types          []Type
+	// Changelog lists the releases of the "Recent changes" section, newest
+	// first; rendered as the x-changelog extension.
+	Changelog []Release
+}
+
+// IsOpenAPI31 reports whether the document is rendered as OpenAPI 3.1.
+func (d *TemplateData) IsOpenAPI31() bool {
+	return d.OpenAPIVersion == OpenAPIVersion31
+}
+
+// HasType reports whether a component schema with the given name is rendered.
+func (d *TemplateData) HasType(name string) bool {
+	for _, t := range d.Types {
+		if t.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasMethod reports whether an operation for the given method is rendered.
+func (d *TemplateData) HasMethod(name string) bool {
+	for _, m := range d.Methods {
+		if m.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookDescription describes the update webhook, linking setWebhook only
+// when its operation is rendered.
+func (d *TemplateData) WebhookDescription() string {
+	setWebhook := "setWebhook"
+	if d.HasMethod(setWebhook) {
+		setWebhook = "[setWebhook](#/paths/~1setWebhook/post)"
+	}
+
+	return "An incoming update, delivered by Telegram to the URL set with " + setWebhook + "."
 }
 
 // Method captures the data needed to describe a Telegram Bot API method in the
 // OpenAPI document.
 type Method struct {
-	Name              string
-	Tags              []string
-	Description       []string
-	Params            []MethodParam
-	Return            *TypeSpec
+	// Name is the method name and operation ID, e.g. "sendMessage".
+	Name string
+	// Tags are the documentation sections the method is listed in.
+	Tags []string
+	// Description holds the Markdown paragraphs of the method description.
+	Description []string
+	// Params are the parameters in documented order.
+	Params []MethodParam
+	// Return is the schema of the result field; nil when not documented.
+	Return *TypeSpec
+	// SupportsMultipart is set when a parameter accepts a file upload.
 	SupportsMultipart bool
+	Deprecated        bool
+	// ReplacedBy names the method to use instead of a deprecated one.
+	ReplacedBy string
+	// Since is the Bot API version that introduced the method, when the
+	// changelog mentions it.
+	Since string
 }
 
 // MethodParam describes a single parameter for a Telegram Bot API method.
@@ -26,15 +90,27 @@ type MethodParam struct {
 	Name        string
 	Description string
 	Required    bool
-	Schema      *TypeSpec
+	// Schema already carries the description, the documented constraints and
+	// an x-order extension with the position of the parameter.
+	Schema *TypeSpec
 }
 
 // Type models a Telegram Bot API object definition in the OpenAPI document.
 type Type struct {
-	Name        string
-	Tag         string
+	// Name is the component schema name, e.g. "Message".
+	Name string
+	// Tag is the documentation section the type is listed in.
+	Tag string
+	// Description holds the Markdown paragraphs of the type description.
 	Description []string
-	Fields      []TypeField
+	// Fields are the object fields in documented order.
+	Fields []TypeField
+	// Union, when set, renders the type as this schema (a oneOf over its
+	// variants) instead of an object with Fields.
+	Union *TypeSpec
+	// Since is the Bot API version that introduced the type, when the
+	// changelog mentions it.
+	Since string
 }
 
 // TypeField represents a field within a Telegram Bot API object definition.
@@ -42,5 +118,6 @@ type TypeField struct {
 	Name        string
 	Description string
 	Required    bool
-	Schema      *TypeSpec
+	// Schema already carries the description and the documented constraints.
+	Schema *TypeSpec
 }
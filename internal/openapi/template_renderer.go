@@ -1,12 +1,18 @@
 package openapi
 
 import (
+	"bytes"
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"text/template"
+
+	"gopkg.in/yaml.v3"
 )
 
 //go:embed openapi.yaml.gotmpl
@@ -18,8 +24,12 @@ var (
 	tmplErr      error
 )
 
+// TemplateBuiltin names the embedded openapi.yaml.gotmpl template.
+const TemplateBuiltin = "builtin"
+
 // RenderTemplate executes the embedded OpenAPI template against the provided
-// data and writes the result to the given writer.
+// data and writes the result to the given writer. It is an alternative to
+// BuildDocument for custom rendering; both produce the same document.
 func RenderTemplate(w io.Writer, data *TemplateData) error {
 	if data == nil {
 		return nil
@@ -30,9 +40,86 @@ func RenderTemplate(w io.Writer, data *TemplateData) error {
 		return err
 	}
 
+	return ExecuteTemplate(w, t, data)
+}
+
+// LoadTemplate parses a user-supplied template file with TemplateFuncs, so it
+// can be rendered with ExecuteTemplate in place of the embedded one.
+func LoadTemplate(path string) (*template.Template, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read template: %w", err)
+	}
+
+	t, err := template.New(filepath.Base(path)).Funcs(TemplateFuncs()).Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+
+	return t, nil
+}
+
+// ExecuteTemplate renders data with a template parsed with TemplateFuncs.
+// OpenAPIVersion defaults to OpenAPIVersion30; for OpenAPIVersion31 the
+// schema functions emit OpenAPI 3.1 schemas.
+func ExecuteTemplate(w io.Writer, t *template.Template, data *TemplateData) error {
+	if data == nil {
+		return nil
+	}
+
+	if data.OpenAPIVersion == "" {
+		withVersion := *data
+		withVersion.OpenAPIVersion = OpenAPIVersion30
+		data = &withVersion
+	}
+
+	if data.IsOpenAPI31() {
+		var err error
+		if t, err = t.Clone(); err != nil {
+			return err
+		}
+
+		t.Funcs(openAPI31Funcs)
+	}
+
 	return t.Execute(w, data)
 }
 
+// TemplateFuncs returns the functions available to OpenAPI templates:
+//
+//   - renderSchema, renderJSONSchema, renderMultipartSchema: a *TypeSpec as
+//     YAML, as is, without binary variants or with them collapsed to a file
+//     upload; indent the result to its position with indent,
+//   - isBinary, isNotBinary, isPureBinary: whether a *TypeSpec accepts files,
+//   - indent n s: prefixes every non-empty line of s with n spaces,
+//   - schemaRef name: the $ref of a component schema,
+//   - quote s: s as a double-quoted YAML scalar, safe for any text,
+//   - toYAML v, toJSON v: any value encoded as YAML or compact JSON,
+//   - join, lower, upper, replace, hasPrefix, hasSuffix, trimSpace: the
+//     strings functions of the same names.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"renderSchema":          renderSchema,
+		"renderJSONSchema":      renderJSONSchema,
+		"renderMultipartSchema": renderMultipartSchema,
+		"indent":                indent,
+		"isBinary":              isBinary,
+		"isNotBinary":           isNotBinary,
+		"isPureBinary":          isPureBinary,
+		"schemaRef":             schemaRef,
+		"quote":                 quote,
+		"toYAML":                toYAML,
+		"toJSON":                toJSON,
+		"join":                  func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"lower":                 strings.ToLower,
+		"upper":                 strings.ToUpper,
+		"replace":               strings.ReplaceAll,
+		"hasPrefix":             strings.HasPrefix,
+		"hasSuffix":             strings.HasSuffix,
+		"trimSpace":             strings.TrimSpace,
+	}
+}
+
 func parsedTemplate() (*template.Template, error) {
 	templateOnce.Do(func() {
 		if len(openapiTemplate) == 0 {
@@ -41,20 +128,51 @@ func parsedTemplate() (*template.Template, error) {
 			return
 		}
 
-		tmpl, tmplErr = template.New("openapi.yaml.gotmpl").Funcs(template.FuncMap{
-			"renderSchema":          renderSchema,
-			"renderJSONSchema":      renderJSONSchema,
-			"renderMultipartSchema": renderMultipartSchema,
-			"indent":                indent,
-			"isBinary":              isBinary,
-			"isNotBinary":           isNotBinary,
-			"isPureBinary":          isPureBinary,
-		}).Parse(string(openapiTemplate))
+		tmpl, tmplErr = template.New("openapi.yaml.gotmpl").Funcs(TemplateFuncs()).Parse(string(openapiTemplate))
 	})
 
 	return tmpl, tmplErr
 }
 
+func schemaRef(name string) string {
+	return "#/components/schemas/" + name
+}
+
+func quote(s string) string {
+	out, _ := json.Marshal(s) //nolint:errchkjson // strings always marshal
+
+	return string(out)
+}
+
+func toYAML(v interface{}) (string, error) {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// toJSON goes through YAML so schemas use their YAML keywords.
+func toJSON(v interface{}) (string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := YAMLToJSON(data)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, out); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
 func isPureBinary(spec *TypeSpec) bool {
 	if spec == nil {
 		return false
@@ -117,7 +235,7 @@ func simplifyJSON(spec *TypeSpec) *TypeSpec {
 	if len(spec.AnyOf) > 0 {
 		anyOf := simplifyList(spec.AnyOf)
 		if len(anyOf) == 1 {
-			return anyOf[0].WithDescription(spec.Description)
+			return withExtensions(anyOf[0].WithDescription(spec.Description), spec)
 		}
 
 		res.AnyOf = anyOf
@@ -126,7 +244,7 @@ func simplifyJSON(spec *TypeSpec) *TypeSpec {
 	if len(spec.OneOf) > 0 {
 		oneOf := simplifyList(spec.OneOf)
 		if len(oneOf) == 1 {
-			return oneOf[0].WithDescription(spec.Description)
+			return withExtensions(oneOf[0].WithDescription(spec.Description), spec)
 		}
 
 		res.OneOf = oneOf
@@ -139,6 +257,16 @@ func simplifyJSON(spec *TypeSpec) *TypeSpec {
 	return &res
 }
 
+// withExtensions carries the extensions of the original schema over to the
+// simplified one, which replaces it entirely.
+func withExtensions(res, orig *TypeSpec) *TypeSpec {
+	if res != nil && len(orig.Extensions) > 0 {
+		res.Extensions = orig.Extensions
+	}
+
+	return res
+}
+
 func simplifyList(specs []TypeSpec) []TypeSpec {
 	var filtered []TypeSpec
 
@@ -169,6 +297,7 @@ func simplifyMultipart(spec *TypeSpec) *TypeSpec {
 			Type:        "string",
 			Format:      "binary",
 			Description: spec.Description,
+			Extensions:  spec.Extensions,
 		}
 	}
 
@@ -2,9 +2,13 @@ package openapi //nolint:testpackage // access internal helpers
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/ghodss/yaml"
 )
 
 func TestRenderSchema(t *testing.T) {
@@ -200,6 +204,22 @@ func TestRenderSpecializedSchemas(t *testing.T) {
 	}
 }
 
+func TestSimplifyKeepsExtensions(t *testing.T) {
+	ext := map[string]interface{}{"x-order": 2}
+	spec := &TypeSpec{
+		OneOf:      []TypeSpec{{Type: "string", Format: "binary"}, {Type: "string"}},
+		Extensions: ext,
+	}
+
+	if got := simplifyJSON(spec); got.Type != "string" || got.Extensions["x-order"] != 2 {
+		t.Fatalf("expected collapsed schema to keep extensions, got %#v", got)
+	}
+
+	if got := simplifyMultipart(spec); got.Format != "binary" || got.Extensions["x-order"] != 2 {
+		t.Fatalf("expected multipart schema to keep extensions, got %#v", got)
+	}
+}
+
 func TestRenderTemplate(t *testing.T) {
 	data := &TemplateData{
 		Title:   "Test API",
@@ -257,4 +277,194 @@ func TestRenderTemplate(t *testing.T) {
 	if !strings.Contains(out, "format: binary") || !strings.Contains(out, "type: string") {
 		t.Errorf("expected photo as binary in multipart section. Output:\n%s", out)
 	}
-}
\ No newline at end of file
+}
+func TestRenderTemplateDeprecatedMethod(t *testing.T) {
+	data := &TemplateData{
+		Title:   "Test API",
+		Version: "1.0.0",
+		Methods: []Method{
+			{Name: "kickChatMember", Deprecated: true, ReplacedBy: "banChatMember"},
+			{Name: "banChatMember"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderTemplate(&buf, data); err != nil {
+		t.Fatalf("RenderTemplate returned error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "operationId: kickChatMember\n      deprecated: true\n      x-replaced-by: banChatMember\n") {
+		t.Fatalf("expected deprecated operation. Output:\n%s", out)
+	}
+
+	if strings.Count(out, "deprecated: true") != 1 {
+		t.Fatalf("expected only one deprecated operation. Output:\n%s", out)
+	}
+}
+
+func TestRenderTemplateMultilineDescription(t *testing.T) {
+	data := &TemplateData{
+		Title:   "Test API",
+		Version: "1.0.0",
+		Types: []Type{
+			{Name: "Message", Description: []string{"First line\nsecond line", "- item"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderTemplate(&buf, data); err != nil {
+		t.Fatalf("RenderTemplate returned error: %v", err)
+	}
+
+	var doc struct {
+		Components struct {
+			Schemas map[string]struct {
+				Description string `json:"description"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+
+	if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("rendered template is not valid YAML: %v", err)
+	}
+
+	if got := doc.Components.Schemas["Message"].Description; got != "First line\nsecond line\n- item" {
+		t.Fatalf("unexpected description %q", got)
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.yaml.gotmpl")
+	text := `openapi: {{ .OpenAPIVersion }}
+info:
+  title: {{ quote .Title }}
+  version: {{ quote .Version }}
+  x-vendor: acme
+paths:
+{{- range .Methods }}
+  /{{ .Name }}:
+    post:
+      operationId: {{ .Name }}
+      tags: {{ toJSON .Tags }}
+      description: {{ quote (join "\n" .Description) }}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+{{ indent 16 (renderSchema .Return) }}
+{{- end }}
+components:
+  schemas:
+{{- range .Types }}
+    {{ .Name }}:
+      $ref: {{ quote (schemaRef (upper .Name)) }}
+{{- end }}
+`
+
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	tpl, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadTemplate returned error: %v", err)
+	}
+
+	data := &TemplateData{
+		Title:   `Bot: "API"`,
+		Version: "7.0",
+		Types:   []Type{{Name: "User"}},
+		Methods: []Method{{
+			Name:        "getMe",
+			Tags:        []string{"Available methods"},
+			Description: []string{"# Returns", "the bot"},
+			Return:      &TypeSpec{Type: "string", Nullable: true},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := ExecuteTemplate(&buf, tpl, data); err != nil {
+		t.Fatalf("ExecuteTemplate returned error: %v", err)
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title string `json:"title"`
+		} `json:"info"`
+		Paths map[string]struct {
+			Post struct {
+				Tags        []string `json:"tags"`
+				Description string   `json:"description"`
+			} `json:"post"`
+		} `json:"paths"`
+	}
+
+	if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("custom template output is not valid YAML: %v\n%s", err, buf.String())
+	}
+
+	op := doc.Paths["/getMe"].Post
+	if doc.OpenAPI != OpenAPIVersion30 || doc.Info.Title != `Bot: "API"` ||
+		op.Description != "# Returns\nthe bot" || len(op.Tags) != 1 {
+		t.Fatalf("unexpected custom template output:\n%s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), "$ref: \"#/components/schemas/USER\"") {
+		t.Errorf("expected schemaRef helper output in\n%s", buf.String())
+	}
+
+	data.OpenAPIVersion = OpenAPIVersion31
+	buf.Reset()
+
+	if err := ExecuteTemplate(&buf, tpl, data); err != nil {
+		t.Fatalf("ExecuteTemplate returned error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `type: [string, "null"]`) {
+		t.Errorf("expected OpenAPI 3.1 schemas from a custom template, got\n%s", buf.String())
+	}
+}
+
+func TestLoadTemplateErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := LoadTemplate(filepath.Join(dir, "missing.gotmpl")); err == nil {
+		t.Fatal("expected error for a missing template")
+	}
+
+	path := filepath.Join(dir, "broken.gotmpl")
+	if err := os.WriteFile(path, []byte("{{ .Title "), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	if _, err := LoadTemplate(path); err == nil {
+		t.Fatal("expected error for an unparsable template")
+	}
+}
+
+func TestTemplateFuncsHelpers(t *testing.T) {
+	funcs := TemplateFuncs()
+
+	for _, name := range []string{"renderSchema", "renderJSONSchema", "indent", "quote", "toYAML", "join"} {
+		if funcs[name] == nil {
+			t.Errorf("expected %s in TemplateFuncs", name)
+		}
+	}
+
+	if got := quote("a: \"b\"\n"); got != `"a: \"b\"\n"` {
+		t.Errorf("unexpected quote output %q", got)
+	}
+
+	if got, err := toYAML(map[string]int{"a": 1}); err != nil || got != "a: 1" {
+		t.Errorf("unexpected toYAML output %q (err %v)", got, err)
+	}
+
+	spec := &TypeSpec{Type: "string", MinLength: new(int)}
+	if got, err := toJSON(spec); err != nil || got != `{"type":"string","minLength":0}` {
+		t.Errorf("unexpected toJSON output %q (err %v)", got, err)
+	}
+}
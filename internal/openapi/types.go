@@ -1,7 +1,15 @@
 package openapi
 
+import "gopkg.in/yaml.v3"
+
 type TypeSpec struct {
-	Type                 string              `yaml:"type,omitempty"`
+	Type string `yaml:"type,omitempty"`
+	// Types, when set, is rendered as an OpenAPI 3.1 type list such as
+	// [string, "null"] in place of Type.
+	Types []string `yaml:"-"`
+	// PropertyOrder lists property names in the order they are rendered;
+	// properties missing from it follow in alphabetical order.
+	PropertyOrder        []string            `yaml:"-"`
 	Format               string              `yaml:"format,omitempty"`
 	Description          string              `yaml:"description,omitempty"`
 	Properties           map[string]TypeSpec `yaml:"properties,omitempty"`
@@ -10,8 +18,10 @@ type TypeSpec struct {
 	AnyOf                []TypeSpec          `yaml:"anyOf,omitempty"`
 	AllOf                []TypeSpec          `yaml:"allOf,omitempty"`
 	Example              string              `yaml:"example,omitempty"`
+	Examples             []interface{}       `yaml:"examples,omitempty"`
 	Items                *TypeSpec           `yaml:"items,omitempty"`
 	Enum                 []interface{}       `yaml:"enum,omitempty"`
+	Const                interface{}         `yaml:"const,omitempty"`
 	Default              interface{}         `yaml:"default,omitempty"`
 	Minimum              *float64            `yaml:"minimum,omitempty"`
 	Maximum              *float64            `yaml:"maximum,omitempty"`
@@ -23,6 +33,7 @@ type TypeSpec struct {
 	UniqueItems          bool                `yaml:"uniqueItems,omitempty"`
 	AdditionalProperties interface{}         `yaml:"additionalProperties,omitempty"` // Can be boolean or TypeSpec
 	Nullable             bool                `yaml:"nullable,omitempty"`
+	Deprecated           bool                `yaml:"deprecated,omitempty"`
 	ReadOnly             bool                `yaml:"readOnly,omitempty"`
 	WriteOnly            bool                `yaml:"writeOnly,omitempty"`
 	Title                string              `yaml:"title,omitempty"`
@@ -31,6 +42,75 @@ type TypeSpec struct {
 	MinProperties        *int                `yaml:"minProperties,omitempty"`
 	Discriminator        *Discriminator      `yaml:"discriminator,omitempty"`
 	Ref                  *TypeRef            `yaml:"$ref,omitempty"`
+	// Extensions holds specification extensions such as x-order, rendered
+	// inline next to the standard keywords.
+	Extensions map[string]interface{} `yaml:",inline"`
+}
+
+// MarshalYAML renders Types as a sequence under the type keyword, which the
+// struct tags alone cannot express next to the scalar Type, and orders
+// properties by PropertyOrder.
+func (s TypeSpec) MarshalYAML() (interface{}, error) {
+	type plain TypeSpec
+
+	if len(s.Types) == 0 && len(s.PropertyOrder) == 0 {
+		return plain(s), nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(plain(s)); err != nil {
+		return nil, err
+	}
+
+	if len(s.Types) > 0 {
+		types := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+		for _, t := range s.Types {
+			types.Content = append(types.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t})
+		}
+
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "type"}
+		node.Content = append([]*yaml.Node{key, types}, node.Content...)
+	}
+
+	if len(s.PropertyOrder) > 0 {
+		orderProperties(&node, s.PropertyOrder)
+	}
+
+	return &node, nil
+}
+
+func orderProperties(node *yaml.Node, order []string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != "properties" {
+			continue
+		}
+
+		props := node.Content[i+1]
+		pairs := make(map[string][]*yaml.Node, len(props.Content)/2)
+
+		for j := 0; j+1 < len(props.Content); j += 2 {
+			pairs[props.Content[j].Value] = props.Content[j : j+2]
+		}
+
+		content := make([]*yaml.Node, 0, len(props.Content))
+
+		for _, name := range order {
+			if pair, ok := pairs[name]; ok {
+				content = append(content, pair...)
+				delete(pairs, name)
+			}
+		}
+
+		for j := 0; j+1 < len(props.Content); j += 2 {
+			if _, ok := pairs[props.Content[j].Value]; ok {
+				content = append(content, props.Content[j:j+2]...)
+			}
+		}
+
+		props.Content = content
+
+		return
+	}
 }
 
 // WithDescription returns a copy of the TypeSpec with the provided description.
@@ -54,6 +134,16 @@ func (s *TypeSpec) WithDescription(desc string) *TypeSpec {
 	return &res
 }
 
+// SetExtension sets a specification extension such as x-order, keeping the
+// extensions already present.
+func (s *TypeSpec) SetExtension(name string, value interface{}) {
+	if s.Extensions == nil {
+		s.Extensions = make(map[string]interface{})
+	}
+
+	s.Extensions[name] = value
+}
+
 type Discriminator struct {
 	PropertyName string            `yaml:"propertyName"`
 	Mapping      map[string]string `yaml:"mapping,omitempty"`
@@ -0,0 +1,354 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnsupportedVersion is returned by Validate for OpenAPI versions other
+// than 3.0 and 3.1.
+var ErrUnsupportedVersion = errors.New("unsupported OpenAPI version")
+
+// ValidationError lists the problems found in an OpenAPI document.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return "invalid OpenAPI document: " + e.Problems[0]
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "invalid OpenAPI document: %d problems", len(e.Problems))
+
+	for _, p := range e.Problems {
+		b.WriteString("\n  - " + p)
+	}
+
+	return b.String()
+}
+
+// Validate loads an OpenAPI document (YAML or JSON) with kin-openapi and runs
+// the full document validation: references must resolve, schemas must be
+// well-formed and OpenAPI 3.0 rules such as "no siblings next to $ref" apply.
+// kin-openapi stops at the first problem, so schemas and paths are validated
+// one by one to report all of them; the returned error is a *ValidationError.
+//
+// kin-openapi only understands OpenAPI 3.0, so a 3.1 document is checked after
+// its 3.1 keywords are rewritten to their 3.0 equivalents, see downgrade.
+func Validate(ctx context.Context, data []byte) error {
+	var header struct {
+		OpenAPI string `yaml:"openapi"`
+	}
+
+	// YAML is a superset of JSON, so this reads the version of both.
+	_ = yaml.Unmarshal(data, &header)
+
+	var webhooks map[string]string
+
+	switch {
+	case strings.HasPrefix(header.OpenAPI, "3.1"):
+		var err error
+		if data, webhooks, err = downgrade(data); err != nil {
+			return &ValidationError{Problems: []string{"load: " + err.Error()}}
+		}
+	case header.OpenAPI != "" && !strings.HasPrefix(header.OpenAPI, "3.0"):
+		return fmt.Errorf("%w %s: only 3.0 and 3.1 documents can be validated", ErrUnsupportedVersion, header.OpenAPI)
+	}
+
+	loader := openapi3.NewLoader()
+
+	doc, err := loader.LoadFromData(data)
+	if err != nil {
+		return &ValidationError{Problems: []string{"load: " + err.Error()}}
+	}
+
+	docErr := doc.Validate(ctx)
+	if docErr == nil {
+		return nil
+	}
+
+	var problems []string
+
+	if doc.Components != nil {
+		for _, name := range sortedKeys(doc.Components.Schemas) {
+			if err := doc.Components.Schemas[name].Validate(ctx); err != nil {
+				problems = append(problems, fmt.Sprintf("components.schemas.%s: %v", name, err))
+			}
+		}
+	}
+
+	if doc.Paths != nil {
+		for _, path := range sortedKeys(doc.Paths.Map()) {
+			if err := doc.Paths.Value(path).Validate(ctx); err != nil {
+				label := "paths." + path
+				if name, ok := webhooks[path]; ok {
+					label = "webhooks." + name
+				}
+
+				problems = append(problems, fmt.Sprintf("%s: %v", label, err))
+			}
+		}
+	}
+
+	// Problems outside of schemas and paths (info, servers, duplicate
+	// operation IDs, ...) are only visible in the document-level error.
+	if len(problems) == 0 {
+		problems = append(problems, docErr.Error())
+	}
+
+	return &ValidationError{Problems: problems}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// webhookPrefix prefixes the paths that webhooks are moved to for validation.
+const webhookPrefix = "/webhooks/"
+
+// downgrade rewrites an OpenAPI 3.1 document to 3.0 so that kin-openapi can
+// validate it. Only the constructs with a 3.0 equivalent are rewritten:
+//
+//   - "type" lists become a single type, or an anyOf of types, and their
+//     "null" entry, like a {type: "null"} anyOf or oneOf alternative, becomes
+//     "nullable";
+//   - "const" becomes a single-value "enum";
+//   - schema "examples" become "example";
+//   - "$ref" siblings other than summary and description move next to an
+//     allOf with the reference;
+//   - webhooks become paths under /webhooks/, returned keyed by path with
+//     the webhook name so problems are reported against the webhook.
+func downgrade(data []byte) ([]byte, map[string]string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("decode spec: %w", err)
+	}
+
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return data, nil, nil
+	}
+
+	doc := root.Content[0]
+	webhooks := map[string]string{}
+
+	if hooks := mappingValue(doc, "webhooks"); hooks != nil && hooks.Kind == yaml.MappingNode {
+		paths := mappingValue(doc, "paths")
+		if paths == nil {
+			paths = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			doc.Content = append(doc.Content, scalar("paths"), paths)
+		}
+
+		for i := 0; i+1 < len(hooks.Content); i += 2 {
+			path := webhookPrefix + hooks.Content[i].Value
+			webhooks[path] = hooks.Content[i].Value
+			paths.Content = append(paths.Content, scalar(path), hooks.Content[i+1])
+		}
+	}
+
+	deleteKeys(doc, "webhooks", "jsonSchemaDialect")
+	mappingValue(doc, "openapi").SetString("3.0.3")
+	downgradeNode(doc, false)
+
+	out, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode spec: %w", err)
+	}
+
+	return out, webhooks, nil
+}
+
+// downgradeNode rewrites the 3.1 keywords of node and its children. When
+// names is set, node maps names (properties, schemas, paths, ...) to objects
+// and its keys are not keywords.
+func downgradeNode(node *yaml.Node, names bool) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			downgradeNode(item, false)
+		}
+
+		return
+	case yaml.MappingNode:
+	default:
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+
+		switch {
+		case names:
+			downgradeNode(value, false)
+		case isDataKeyword(key):
+			// Example and default values are data, not schemas.
+		case isNameMap(key):
+			downgradeNode(value, true)
+		default:
+			downgradeNode(value, false)
+		}
+	}
+
+	if names {
+		return
+	}
+
+	downgradeSchema(node)
+}
+
+// downgradeSchema rewrites the 3.1 keywords of a single object whose
+// children are already rewritten.
+func downgradeSchema(node *yaml.Node) {
+	if typ := mappingValue(node, "type"); typ != nil && typ.Kind == yaml.SequenceNode {
+		var types []*yaml.Node
+
+		for _, item := range typ.Content {
+			if item.Value == "null" {
+				setNullable(node)
+			} else {
+				types = append(types, item)
+			}
+		}
+
+		switch len(types) {
+		case 0:
+			deleteKeys(node, "type")
+		case 1:
+			*typ = *types[0]
+		default:
+			alternatives := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			for _, t := range types {
+				alternatives.Content = append(alternatives.Content, mapping(scalar("type"), t))
+			}
+
+			deleteKeys(node, "type")
+			node.Content = append(node.Content, scalar("anyOf"), alternatives)
+		}
+	}
+
+	for _, key := range []string{"anyOf", "oneOf"} {
+		alternatives := mappingValue(node, key)
+		if alternatives == nil || alternatives.Kind != yaml.SequenceNode {
+			continue
+		}
+
+		kept := alternatives.Content[:0]
+
+		for _, item := range alternatives.Content {
+			if isNullSchema(item) {
+				setNullable(node)
+
+				continue
+			}
+
+			kept = append(kept, item)
+		}
+
+		alternatives.Content = kept
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		switch {
+		case key.Value == "const":
+			key.SetString("enum")
+			node.Content[i+1] = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{value}}
+		case key.Value == "examples" && value.Kind == yaml.SequenceNode && len(value.Content) > 0:
+			key.SetString("example")
+			node.Content[i+1] = value.Content[0]
+		}
+	}
+
+	if ref := mappingValue(node, "$ref"); ref != nil && len(node.Content) > 2 {
+		deleteKeys(node, "$ref", "summary", "description")
+
+		if len(node.Content) > 0 {
+			node.Content = append(node.Content, scalar("allOf"), &yaml.Node{
+				Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{mapping(scalar("$ref"), ref)},
+			})
+		} else {
+			node.Content = append(node.Content, scalar("$ref"), ref)
+		}
+	}
+}
+
+// isDataKeyword reports whether the value of key is data rather than part
+// of the document structure.
+func isDataKeyword(key string) bool {
+	switch key {
+	case "example", "examples", "default", "enum", "const":
+		return true
+	}
+
+	return strings.HasPrefix(key, "x-")
+}
+
+// isNameMap reports whether the value of key maps names to objects.
+func isNameMap(key string) bool {
+	switch key {
+	case "properties", "patternProperties", "$defs", "schemas", "paths", "responses", "parameters",
+		"requestBodies", "headers", "securitySchemes", "links", "callbacks", "content", "encoding", "mapping":
+		return true
+	}
+
+	return false
+}
+
+func isNullSchema(node *yaml.Node) bool {
+	return node.Kind == yaml.MappingNode && len(node.Content) == 2 && node.Content[0].Value == "type" &&
+		node.Content[1].Value == "null"
+}
+
+func setNullable(node *yaml.Node) {
+	deleteKeys(node, "nullable")
+
+	node.Content = append(node.Content, scalar("nullable"),
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func deleteKeys(node *yaml.Node, keys ...string) {
+	kept := node.Content[:0]
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !slices.Contains(keys, node.Content[i].Value) {
+			kept = append(kept, node.Content[i], node.Content[i+1])
+		}
+	}
+
+	node.Content = kept
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func mapping(key, value *yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{key, value}}
+}
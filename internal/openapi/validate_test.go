@@ -0,0 +1,157 @@
+package openapi //nolint:testpackage // tests share fixtures with the renderer tests
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const invalidSpec = `openapi: 3.0.0
+info:
+  title: Broken
+  version: "1.0"
+paths:
+  /getMe:
+    post:
+      operationId: getMe
+components:
+  schemas:
+    User:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: integer
+          default: "one"
+    Chat:
+      type: strange
+`
+
+func TestValidate(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderTemplate(&buf, &TemplateData{
+		Title:   "Telegram Bot API",
+		Version: "7.0",
+		Types: []Type{
+			{Name: "ResponseParameters"},
+			{Name: "User", Fields: []TypeField{{Name: "id", Required: true, Schema: &TypeSpec{Type: "integer"}}}},
+		},
+		Methods: []Method{
+			{Name: "getMe", Description: []string{"Returns the bot."}, Return: &TypeSpec{Ref: &TypeRef{Name: "User"}}},
+		},
+	}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+
+	if err := Validate(t.Context(), buf.Bytes()); err != nil {
+		t.Fatalf("expected rendered spec to be valid, got %v", err)
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	err := Validate(t.Context(), []byte(invalidSpec))
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	if len(validationErr.Problems) != 3 {
+		t.Fatalf("expected three problems, got %q", validationErr.Problems)
+	}
+
+	if !strings.HasPrefix(validationErr.Problems[0], "components.schemas.Chat: ") ||
+		!strings.HasPrefix(validationErr.Problems[1], "components.schemas.User: ") ||
+		!strings.HasPrefix(validationErr.Problems[2], "paths./getMe: ") {
+		t.Fatalf("unexpected problems %q", validationErr.Problems)
+	}
+
+	if msg := err.Error(); !strings.Contains(msg, "3 problems\n  - components.schemas.Chat") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestValidateUnresolvedRef(t *testing.T) {
+	spec := strings.Replace(invalidSpec, "type: strange", "$ref: '#/components/schemas/Missing'", 1)
+
+	err := Validate(t.Context(), []byte(spec))
+	if err == nil || !strings.Contains(err.Error(), "load: ") || !strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("expected load error for dangling reference, got %v", err)
+	}
+}
+
+const spec31 = `openapi: 3.1.0
+info:
+  title: Telegram Bot API
+  version: "7.0"
+paths:
+  /getMe:
+    post:
+      operationId: getMe
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+webhooks:
+  update:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/User'
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        type:
+          type: string
+          const: private
+        username:
+          type: [string, "null"]
+          examples: [durov]
+        id:
+          type: [integer, string]
+        photo:
+          description: Profile photo
+          $ref: '#/components/schemas/Photo'
+        chat:
+          description: Chat
+          anyOf:
+            - $ref: '#/components/schemas/Photo'
+            - type: "null"
+    Photo:
+      type: object
+`
+
+func TestValidateOpenAPI31(t *testing.T) {
+	if err := Validate(t.Context(), []byte(spec31)); err != nil {
+		t.Fatalf("expected OpenAPI 3.1 spec to be valid, got %v", err)
+	}
+
+	for _, tc := range []struct{ from, to, problem string }{
+		{from: "type: [string, \"null\"]", to: "type: [strange, \"null\"]", problem: "components.schemas.User: "},
+		{from: "Photo:\n      type: object", to: "Photo:\n      type: strange", problem: "components.schemas.Photo: "},
+		{from: "User'\n      responses", to: "User'\n              type: strange\n      responses", problem: "webhooks.update: "},
+	} {
+		spec := strings.Replace(spec31, tc.from, tc.to, 1)
+
+		var validationErr *ValidationError
+		if err := Validate(t.Context(), []byte(spec)); !errors.As(err, &validationErr) ||
+			!strings.HasPrefix(validationErr.Problems[0], tc.problem) {
+			t.Errorf("expected a %q problem, got %v", tc.problem, err)
+		}
+	}
+
+	if err := Validate(t.Context(), []byte("openapi: 3.2.0\n")); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+}
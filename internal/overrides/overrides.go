@@ -0,0 +1,410 @@
+// Package overrides applies manual corrections to the parsed documentation,
+// between parsing and rendering.
+//
+// An overrides file is YAML. Names and paths are path.Match patterns; a path
+// is "Type.field" for a type field and "method.param" for a method parameter.
+// Entries are applied in order: types first, then methods, then fields.
+//
+//	types:
+//	  - name: ChatMemberUpdated
+//	    rename: ChatMemberUpdate
+//	  - name: ResponseParameters
+//	    add:                       # only when the docs lack the type
+//	      fields:
+//	        - {name: retry_after, type: Integer}
+//	  - name: PassportData
+//	    drop: true
+//	methods:
+//	  - name: getChatMenuButton
+//	    return: MenuButton
+//	fields:
+//	  - path: Chat.linked_chat_id
+//	    int64: true
+//	  - path: sendMessage.parse_mode
+//	    required: false
+//	    enum: [HTML, Markdown, MarkdownV2]
+//	  - path: Message.via_bot
+//	    drop: true
+//
+// An entry that matches nothing, because the documentation changed, is
+// reported as a warning so stale corrections can be cleaned up.
+package overrides
+
+import (
+	"bytes"
+	"cmp"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/metalagman/tgbotspec/internal/ir"
+	"github.com/metalagman/tgbotspec/internal/parser"
+)
+
+//go:embed defaults.yaml
+var defaults []byte
+
+// DefaultName names the bundled default overrides in diagnostics.
+const DefaultName = "default overrides"
+
+// File is a set of overrides.
+type File struct {
+	// Name identifies the file in diagnostics.
+	Name    string           `yaml:"-"`
+	Types   []TypeOverride   `yaml:"types"`
+	Methods []MethodOverride `yaml:"methods"`
+	Fields  []FieldOverride  `yaml:"fields"`
+	// quiet suppresses the stale entry warnings, for the defaults, which
+	// are generic rules that need not match every documentation snapshot.
+	quiet bool
+}
+
+// TypeOverride renames, drops or adds types.
+type TypeOverride struct {
+	Name   string `yaml:"name"`
+	Rename string `yaml:"rename"`
+	Drop   bool   `yaml:"drop"`
+	// Add defines the type when the documentation lacks it. Such an entry
+	// is a fallback and is never reported as stale.
+	Add *NewType `yaml:"add"`
+}
+
+// NewType is a type added by an override; Name is taken from the entry.
+type NewType struct {
+	Tag         string     `yaml:"tag"`
+	Description []string   `yaml:"description"`
+	Fields      []NewField `yaml:"fields"`
+	OneOf       []string   `yaml:"one_of"`
+}
+
+// NewField is a field of an added type.
+type NewField struct {
+	Name string `yaml:"name"`
+	// Type is spelled as in the docs, e.g. "Array of PhotoSize".
+	Type        string   `yaml:"type"`
+	Required    bool     `yaml:"required"`
+	Description string   `yaml:"description"`
+	Enum        []string `yaml:"enum"`
+	Int64       bool     `yaml:"int64"`
+}
+
+// MethodOverride corrects or drops methods.
+type MethodOverride struct {
+	Name string `yaml:"name"`
+	// Return forces the return type, spelled as in the docs.
+	Return string `yaml:"return"`
+	Drop   bool   `yaml:"drop"`
+}
+
+// FieldOverride corrects or drops type fields and method parameters. Unset
+// properties are left as parsed; an empty enum list clears the enum.
+type FieldOverride struct {
+	Path        string   `yaml:"path"`
+	Type        string   `yaml:"type"`
+	Required    *bool    `yaml:"required"`
+	Enum        []string `yaml:"enum"`
+	Int64       *bool    `yaml:"int64"`
+	Description *string  `yaml:"description"`
+	Drop        bool     `yaml:"drop"`
+}
+
+// Default returns the bundled default overrides.
+func Default() *File {
+	f, err := Parse(DefaultName, bytes.NewReader(defaults))
+	if err != nil {
+		panic(err)
+	}
+
+	f.quiet = true
+
+	return f
+}
+
+// Load reads an overrides file.
+func Load(filename string) (*File, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read overrides: %w", err)
+	}
+
+	return Parse(filename, bytes.NewReader(data))
+}
+
+// Parse decodes overrides named name from r. Unknown keys are rejected so a
+// misspelled property does not silently do nothing.
+func Parse(name string, r io.Reader) (*File, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	f := &File{}
+	if err := dec.Decode(f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decode overrides %s: %w", name, err)
+	}
+
+	f.Name = name
+
+	if err := f.check(); err != nil {
+		return nil, fmt.Errorf("overrides %s: %w", name, err)
+	}
+
+	return f, nil
+}
+
+func (f *File) check() error {
+	for _, o := range f.Types {
+		if err := checkPattern("type", o.Name); err != nil {
+			return err
+		}
+	}
+
+	for _, o := range f.Methods {
+		if err := checkPattern("method", o.Name); err != nil {
+			return err
+		}
+	}
+
+	for _, o := range f.Fields {
+		if err := checkPattern("field", o.Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkPattern(kind, pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("%s override without a name", kind)
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("%s override %q: %w", kind, pattern, err)
+	}
+
+	return nil
+}
+
+// Apply corrects doc in place. Entries that match nothing are reported as
+// warnings to report, which may be nil.
+func (f *File) Apply(doc *ir.Document, report *parser.Report) {
+	for _, o := range f.Types {
+		if !applyType(doc, o) && o.Add == nil {
+			f.stale(report, "type", o.Name)
+		}
+	}
+
+	for _, o := range f.Methods {
+		if !applyMethod(doc, o) {
+			f.stale(report, "method", o.Name)
+		}
+	}
+
+	for _, o := range f.Fields {
+		if !applyField(doc, o) {
+			f.stale(report, "field", o.Path)
+		}
+	}
+}
+
+func (f *File) stale(report *parser.Report, kind, pattern string) {
+	if f.quiet {
+		return
+	}
+
+	report.Warnf(pattern, "", "%s: %s override %s no longer matches anything", f.Name, kind, pattern)
+}
+
+// applyType applies a type override and reports whether it matched.
+func applyType(doc *ir.Document, o TypeOverride) bool {
+	matched := false
+
+	for i := 0; i < len(doc.Types); i++ {
+		name := doc.Types[i].Name
+		if ok, _ := path.Match(o.Name, name); !ok {
+			continue
+		}
+
+		matched = true
+
+		switch {
+		case o.Drop:
+			doc.Types = slices.Delete(doc.Types, i, i+1)
+			i--
+		case o.Rename != "":
+			doc.Types[i].Name = o.Rename
+			renameRefs(doc, name, o.Rename)
+		}
+	}
+
+	if !matched && o.Add != nil {
+		doc.Types = append(doc.Types, o.Add.irType(o.Name))
+	}
+
+	if o.Rename != "" || o.Add != nil {
+		slices.SortStableFunc(doc.Types, func(a, b ir.Type) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+	}
+
+	return matched
+}
+
+// applyMethod applies a method override and reports whether it matched.
+func applyMethod(doc *ir.Document, o MethodOverride) bool {
+	matched := false
+
+	for i := 0; i < len(doc.Methods); i++ {
+		if ok, _ := path.Match(o.Name, doc.Methods[i].Name); !ok {
+			continue
+		}
+
+		matched = true
+
+		switch {
+		case o.Drop:
+			doc.Methods = slices.Delete(doc.Methods, i, i+1)
+			i--
+		case o.Return != "":
+			doc.Methods[i].Return = o.Return
+		}
+	}
+
+	return matched
+}
+
+// applyField applies a field override to the fields of all types and the
+// parameters of all methods, and reports whether it matched.
+func applyField(doc *ir.Document, o FieldOverride) bool {
+	matched := false
+
+	for i := range doc.Types {
+		if applyFields(&doc.Types[i].Fields, doc.Types[i].Name, o) {
+			matched = true
+		}
+	}
+
+	for i := range doc.Methods {
+		if applyFields(&doc.Methods[i].Params, doc.Methods[i].Name, o) {
+			matched = true
+		}
+	}
+
+	return matched
+}
+
+func applyFields(fields *[]ir.Field, owner string, o FieldOverride) bool {
+	matched := false
+	kept := (*fields)[:0]
+
+	for _, field := range *fields {
+		if ok, _ := path.Match(o.Path, owner+"."+field.Name); ok {
+			matched = true
+
+			if o.Drop {
+				continue
+			}
+
+			o.apply(&field)
+		}
+
+		kept = append(kept, field)
+	}
+
+	*fields = kept
+
+	return matched
+}
+
+func (o FieldOverride) apply(field *ir.Field) {
+	if o.Type != "" {
+		field.Type = o.Type
+	}
+
+	if o.Required != nil {
+		field.Required = *o.Required
+	}
+
+	if o.Enum != nil {
+		field.Enum = o.Enum
+		if len(o.Enum) == 0 {
+			field.Enum = nil
+		}
+	}
+
+	if o.Int64 != nil {
+		field.Int64 = *o.Int64
+	}
+
+	if o.Description != nil {
+		field.Description = *o.Description
+	}
+}
+
+// renameRefs points the references to a renamed type at its new name.
+func renameRefs(doc *ir.Document, from, to string) {
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(from) + `\b`)
+	rename := func(s string) string {
+		return word.ReplaceAllLiteralString(s, to)
+	}
+
+	for i := range doc.Types {
+		t := &doc.Types[i]
+		for j := range t.Fields {
+			t.Fields[j].Type = rename(t.Fields[j].Type)
+		}
+
+		for j := range t.OneOf {
+			t.OneOf[j] = rename(t.OneOf[j])
+		}
+	}
+
+	for i := range doc.Methods {
+		m := &doc.Methods[i]
+		m.Return = rename(m.Return)
+
+		for j := range m.Params {
+			m.Params[j].Type = rename(m.Params[j].Type)
+		}
+	}
+
+	for i := range doc.Changelog {
+		for j := range doc.Changelog[i].Items {
+			item := &doc.Changelog[i].Items[j]
+			for k := range item.Refs {
+				item.Refs[k] = rename(item.Refs[k])
+			}
+
+			for k := range item.Added {
+				item.Added[k] = rename(item.Added[k])
+			}
+		}
+	}
+}
+
+func (t *NewType) irType(name string) ir.Type {
+	res := ir.Type{
+		Name:        name,
+		Tag:         t.Tag,
+		Description: t.Description,
+		OneOf:       t.OneOf,
+	}
+
+	for _, f := range t.Fields {
+		res.Fields = append(res.Fields, ir.Field{
+			Name:        f.Name,
+			Type:        f.Type,
+			Required:    f.Required,
+			Description: f.Description,
+			Annotations: ir.Annotations{Enum: f.Enum, Int64: f.Int64},
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,247 @@
+package overrides_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/metalagman/tgbotspec/internal/ir"
+	"github.com/metalagman/tgbotspec/internal/overrides"
+	"github.com/metalagman/tgbotspec/internal/parser"
+)
+
+func testDocument() *ir.Document {
+	return &ir.Document{
+		Version: ir.Version,
+		Types: []ir.Type{
+			{Name: "ChatMemberUpdated", Fields: []ir.Field{
+				{Name: "chat", Type: "Chat", Required: true},
+				{Name: "via_join_request", Type: "Boolean"},
+			}},
+			{Name: "Message", Fields: []ir.Field{
+				{Name: "chat", Type: "Chat", Required: true},
+				{Name: "sender_chat_id", Type: "Integer"},
+			}},
+			{Name: "Update", Fields: []ir.Field{
+				{Name: "chat_member", Type: "ChatMemberUpdated"},
+				{Name: "my_chat_members", Type: "Array of ChatMemberUpdated"},
+			}},
+		},
+		Methods: []ir.Method{
+			{Name: "getChatMenuButton", Return: "String", Params: []ir.Field{
+				{Name: "chat_id", Type: "Integer"},
+			}},
+			{Name: "sendMessage", Return: "Message", Params: []ir.Field{
+				{Name: "chat_id", Type: "Integer or String", Required: true},
+				{Name: "parse_mode", Type: "String", Annotations: ir.Annotations{Enum: []string{"HTML"}}},
+			}},
+		},
+		Changelog: []ir.Release{{Version: "7.0", Items: []ir.ChangeItem{
+			{Text: "Added ChatMemberUpdated.", Refs: []string{"ChatMemberUpdated"}, Added: []string{"ChatMemberUpdated"}},
+		}}},
+	}
+}
+
+func mustParse(t *testing.T, data string) *overrides.File {
+	t.Helper()
+
+	f, err := overrides.Parse("test.yaml", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	return f
+}
+
+func TestApply(t *testing.T) {
+	f := mustParse(t, `
+types:
+  - name: ChatMemberUpdated
+    rename: ChatMemberChange
+  - name: ResponseParameters
+    add:
+      fields:
+        - {name: retry_after, type: Integer}
+  - name: Message
+    add:
+      fields:
+        - {name: ignored, type: String}
+methods:
+  - name: getChatMenuButton
+    return: MenuButton
+fields:
+  - path: sendMessage.parse_mode
+    required: true
+    enum: []
+  - path: "*.sender_chat_id"
+    type: Integer or String
+    int64: true
+  - path: ChatMemberChange.via_join_request
+    drop: true
+`)
+
+	doc := testDocument()
+	report := &parser.Report{}
+	f.Apply(doc, report)
+
+	if len(report.Diagnostics) != 0 {
+		t.Fatalf("unexpected diagnostics %+v", report.Diagnostics)
+	}
+
+	names := make([]string, 0, len(doc.Types))
+	for _, typ := range doc.Types {
+		names = append(names, typ.Name)
+	}
+
+	if !reflect.DeepEqual(names, []string{"ChatMemberChange", "Message", "ResponseParameters", "Update"}) {
+		t.Fatalf("unexpected types %v", names)
+	}
+
+	if got := doc.Types[0].Fields; len(got) != 1 || got[0].Name != "chat" {
+		t.Errorf("via_join_request not dropped: %+v", got)
+	}
+
+	if got := doc.Types[1].Fields; len(got) != 2 || got[1].Type != "Integer or String" || !got[1].Int64 {
+		t.Errorf("Message kept or lost fields: %+v", got)
+	}
+
+	if got := doc.Types[2].Fields; len(got) != 1 || got[0].Name != "retry_after" || got[0].Type != "Integer" {
+		t.Errorf("ResponseParameters not added: %+v", got)
+	}
+
+	if got := doc.Types[3].Fields; got[0].Type != "ChatMemberChange" || got[1].Type != "Array of ChatMemberChange" {
+		t.Errorf("references not renamed: %+v", got)
+	}
+
+	if item := doc.Changelog[0].Items[0]; item.Refs[0] != "ChatMemberChange" || item.Added[0] != "ChatMemberChange" {
+		t.Errorf("changelog references not renamed: %+v", item)
+	}
+
+	if got := doc.Methods[0].Return; got != "MenuButton" {
+		t.Errorf("return type not forced: %q", got)
+	}
+
+	if got := doc.Methods[1].Params[1]; !got.Required || got.Enum != nil {
+		t.Errorf("parse_mode not corrected: %+v", got)
+	}
+}
+
+func TestApplyDrop(t *testing.T) {
+	f := mustParse(t, `
+types:
+  - name: Chat*
+    drop: true
+methods:
+  - name: get*
+    drop: true
+`)
+
+	doc := testDocument()
+	f.Apply(doc, nil)
+
+	if len(doc.Types) != 2 || doc.Types[0].Name != "Message" {
+		t.Errorf("types not dropped: %+v", doc.Types)
+	}
+
+	if len(doc.Methods) != 1 || doc.Methods[0].Name != "sendMessage" {
+		t.Errorf("methods not dropped: %+v", doc.Methods)
+	}
+}
+
+func TestApplyStale(t *testing.T) {
+	f := mustParse(t, `
+types:
+  - name: Gone
+    drop: true
+methods:
+  - name: sendGone
+    return: Message
+fields:
+  - path: Message.gone
+    required: true
+`)
+
+	report := &parser.Report{}
+	f.Apply(testDocument(), report)
+
+	anchors := make([]string, 0, len(report.Diagnostics))
+	for _, d := range report.Diagnostics {
+		if d.Severity != parser.SeverityWarning || !strings.Contains(d.Message, "test.yaml") {
+			t.Errorf("unexpected diagnostic %+v", d)
+		}
+
+		anchors = append(anchors, d.Anchor)
+	}
+
+	if !reflect.DeepEqual(anchors, []string{"Gone", "sendGone", "Message.gone"}) {
+		t.Fatalf("expected stale overrides to be reported, got %+v", report.Diagnostics)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	doc := testDocument()
+	report := &parser.Report{}
+	overrides.Default().Apply(doc, report)
+
+	if len(report.Diagnostics) != 0 {
+		t.Fatalf("default overrides must not report stale entries, got %+v", report.Diagnostics)
+	}
+
+	// Whether an identifier is int64 is left to its description.
+	for _, param := range doc.Methods[1].Params {
+		if param.Int64 {
+			t.Errorf("unexpected int64 flag on sendMessage.%s", param.Name)
+		}
+	}
+
+	if doc.Types[1].Fields[1].Int64 {
+		t.Errorf("unexpected int64 flag on Message.sender_chat_id")
+	}
+
+	if doc.Types[2].Name != "ResponseParameters" || len(doc.Types[2].Fields) != 2 {
+		t.Errorf("ResponseParameters not added: %+v", doc.Types[2])
+	}
+
+	if !doc.Types[2].Fields[0].Int64 {
+		t.Errorf("ResponseParameters.migrate_to_chat_id not marked int64")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"unknown key":  "methods:\n  - name: getMe\n    returns: User\n",
+		"no name":      "types:\n  - drop: true\n",
+		"bad pattern":  "fields:\n  - path: \"[\"\n    drop: true\n",
+		"invalid yaml": "types: [",
+	} {
+		if _, err := overrides.Parse("test.yaml", strings.NewReader(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := overrides.Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overrides.yaml")
+	if err := os.WriteFile(path, []byte("methods:\n  - name: getMe\n    return: User\n"), 0o600); err != nil {
+		t.Fatalf("write overrides: %v", err)
+	}
+
+	f, err := overrides.Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if f.Name != path || len(f.Methods) != 1 || f.Methods[0].Return != "User" {
+		t.Fatalf("unexpected overrides %+v", f)
+	}
+
+	if _, err := overrides.Parse("empty.yaml", strings.NewReader("")); err != nil {
+		t.Fatalf("empty overrides must parse: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package parser
+
+import "github.com/metalagman/tgbotspec/internal/openapi"
+
+// Annotations carries schema hints recovered from the prose description of a
+// field or parameter, which the HTML tables do not express structurally.
+type Annotations struct {
+	// Enum lists the closed set of string values the description allows,
+	// e.g. “private”, “group”, “supergroup” or “channel” for Chat.type.
+	// Constant fields ("always “creator”") have a single value.
+	Enum []string
+	// Int64 marks identifiers that may exceed 32 bits; integer parts of the
+	// schema, including those inside "Integer or String" unions, get the
+	// int64 format while the union itself is kept.
+	Int64 bool
+	Constraints
+	// Default is the raw value of a "Defaults to X" clause, coerced to the
+	// schema type by Apply.
+	Default string
+	Deprecation
+}
+
+// ParseAnnotations extracts all supported hints from a description.
+func ParseAnnotations(description string) Annotations {
+	res := Annotations{
+		Enum:        parseEnum(description),
+		Constraints: parseConstraints(description),
+		Default:     parseDefault(description),
+		Deprecation: parseDeprecation(description),
+	}
+
+	if res.Enum == nil {
+		if v := parseConstant(description); v != "" {
+			res.Enum = []string{v}
+		}
+	}
+
+	return res
+}
+
+// Constant returns the value of a field that can only hold one value.
+func (a Annotations) Constant() (string, bool) {
+	if len(a.Enum) != 1 {
+		return "", false
+	}
+
+	return a.Enum[0], true
+}
+
+// Apply copies the annotations onto spec and returns it. Enums and length
+// bounds are only set on plain string scalars, value bounds on numbers and
+// size bounds on arrays, so unions and binary uploads stay untouched.
+func (a Annotations) Apply(spec *openapi.TypeSpec) *openapi.TypeSpec {
+	if spec == nil {
+		return nil
+	}
+
+	if a.Int64 {
+		setInt64Format(spec)
+	}
+
+	if len(a.Enum) > 0 && spec.Type == "string" && spec.Format == "" {
+		spec.Enum = make([]interface{}, 0, len(a.Enum))
+		for _, v := range a.Enum {
+			spec.Enum = append(spec.Enum, v)
+		}
+	}
+
+	a.Constraints.apply(spec)
+
+	if spec.Default == nil {
+		spec.Default = coerceDefault(a.Default, spec)
+	}
+
+	if a.Deprecated {
+		spec.Deprecated = true
+
+		if a.ReplacedBy != "" {
+			spec.SetExtension("x-replaced-by", a.ReplacedBy)
+		}
+	}
+
+	return spec
+}
+
+func (c Constraints) apply(spec *openapi.TypeSpec) {
+	switch spec.Type {
+	case "string":
+		if spec.Format != "" {
+			return
+		}
+
+		if c.MinLength != nil && *c.MinLength > 0 {
+			spec.MinLength = c.MinLength
+		}
+
+		spec.MaxLength = c.MaxLength
+	case "integer", "number":
+		if c.Min != nil {
+			spec.Minimum = toFloat(*c.Min)
+		}
+
+		if c.Max != nil {
+			spec.Maximum = toFloat(*c.Max)
+		}
+	case "array":
+		spec.MinItems = c.Min
+		spec.MaxItems = c.Max
+	}
+}
+
+func toFloat(v int) *float64 {
+	f := float64(v)
+
+	return &f
+}
+
+func setInt64Format(spec *openapi.TypeSpec) {
+	if spec.Type == "integer" {
+		spec.Format = "int64"
+	}
+
+	for i := range spec.OneOf {
+		setInt64Format(&spec.OneOf[i])
+	}
+
+	for i := range spec.AnyOf {
+		setInt64Format(&spec.AnyOf[i])
+	}
+
+	if spec.Items != nil {
+		setInt64Format(spec.Items)
+	}
+}
@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// changelogAnchor names the section listing the latest releases.
+const changelogAnchor = "recent-changes"
+
+var (
+	releasePattern = regexp.MustCompile(`^Bot API (\d+(?:\.\d+)+)$`)
+	// introducedPattern matches the wording that precedes new types and
+	// methods: "Added the class", "Added the methods", "Added new type".
+	introducedPattern = regexp.MustCompile(
+		`(?i)\badded\s+(?:the\s+|new\s+)?(?:class|type|method|object)(?:es|s)?\b`)
+	// notIntroducedPattern matches wording between that clause and a link
+	// showing the link is not one of the new items: "Added the method X to
+	// the class Y", "Added the method X, returning a Y".
+	notIntroducedPattern = regexp.MustCompile(
+		`(?i)\b(?:to|in|of|for|from|with|by|as|an?|which|that|describing|returning|representing|containing|` +
+			`field|fields|parameter|parameters)\b|[.;:]`)
+)
+
+// Release is one Bot API release of the changelog.
+type Release struct {
+	// Version is the Bot API version, e.g. "7.2".
+	Version string
+	// Date is the release date as YYYY-MM-DD, or as written in the docs when
+	// it cannot be parsed.
+	Date  string
+	Items []ChangeItem
+}
+
+// ChangeItem is one bullet of a release.
+type ChangeItem struct {
+	// Text is the Markdown of the bullet without its nested bullets, which
+	// are items of their own.
+	Text string
+	// Refs names the types and methods the bullet links to.
+	Refs []string
+	// Added names the types and methods the bullet introduces, recognized by
+	// wording such as "Added the method X" or "Added the classes X and Y".
+	Added []string
+}
+
+// ParseChangelog extracts the releases listed in the "Recent changes" section,
+// newest first. It returns nil when the section is missing.
+func ParseChangelog(doc *goquery.Document) []Release {
+	heading := doc.Find("a.anchor[name='" + changelogAnchor + "']").First().Parent()
+	if heading.Length() == 0 {
+		return nil
+	}
+
+	var (
+		res  []Release
+		date string
+	)
+
+	for node := heading.Next(); node.Length() > 0 && !isSectionHeading(node); node = node.Next() {
+		switch goquery.NodeName(node) {
+		case "h4":
+			date = releaseDate(strings.TrimSpace(node.Text()))
+		case "p":
+			if m := releasePattern.FindStringSubmatch(strings.TrimSpace(node.Find("strong").First().Text())); m != nil {
+				res = append(res, Release{Version: m[1], Date: date})
+			}
+		case "ul", "ol":
+			if len(res) == 0 {
+				continue
+			}
+
+			release := &res[len(res)-1]
+
+			node.Find("li").Each(func(_ int, li *goquery.Selection) {
+				if item, ok := changeItem(li); ok {
+					release.Items = append(release.Items, item)
+				}
+			})
+		}
+	}
+
+	return res
+}
+
+// Introduced maps the types and methods added by the releases to the oldest
+// release that added them.
+func Introduced(releases []Release) map[string]string {
+	res := make(map[string]string)
+
+	for _, release := range releases {
+		for _, item := range release.Items {
+			for _, name := range item.Added {
+				res[name] = release.Version
+			}
+		}
+	}
+
+	return res
+}
+
+func isSectionHeading(node *goquery.Selection) bool {
+	name := goquery.NodeName(node)
+
+	return name == "h1" || name == "h2" || name == "h3"
+}
+
+func releaseDate(text string) string {
+	t, err := time.Parse("January 2, 2006", text)
+	if err != nil {
+		return text
+	}
+
+	return t.Format(time.DateOnly)
+}
+
+func changeItem(li *goquery.Selection) (ChangeItem, bool) {
+	own := li.Clone()
+	own.Find("ul, ol").Remove()
+
+	item := ChangeItem{Text: Markdown(own)}
+	if item.Text == "" {
+		return item, false
+	}
+
+	var prefix strings.Builder
+
+	walkLinks(own, &prefix, func(name string) {
+		item.Refs = appendUnique(item.Refs, name)
+
+		if isIntroduced(prefix.String()) {
+			item.Added = appendUnique(item.Added, name)
+		}
+	})
+
+	return item, true
+}
+
+// walkLinks calls fn for every link to a type or method below sel, with the
+// text preceding the link written to prefix.
+func walkLinks(sel *goquery.Selection, prefix *strings.Builder, fn func(name string)) {
+	sel.Contents().Each(func(_ int, node *goquery.Selection) {
+		switch {
+		case goquery.NodeName(node) == "#text":
+			prefix.WriteString(node.Text())
+		case goquery.NodeName(node) == "a":
+			if name, ok := refName(node); ok {
+				fn(name)
+			}
+
+			prefix.WriteString(node.Text())
+		default:
+			walkLinks(node, prefix, fn)
+		}
+	})
+}
+
+// isIntroduced reports whether the text before a link ends in a clause that
+// introduces it, such as "Added the classes X, Y and ".
+func isIntroduced(prefix string) bool {
+	matches := introducedPattern.FindAllStringIndex(prefix, -1)
+	if len(matches) == 0 {
+		return false
+	}
+
+	return !notIntroducedPattern.MatchString(prefix[matches[len(matches)-1][1]:])
+}
+
+// refName returns the type or method a node links to, if any.
+func refName(node *goquery.Selection) (string, bool) {
+	href, _ := node.Attr("href")
+	text := strings.TrimSpace(node.Text())
+
+	anchor, ok := strings.CutPrefix(strings.TrimSpace(href), "#")
+	if !ok || !strings.EqualFold(anchor, text) || !containsExactlyOneWord(text) {
+		return "", false
+	}
+
+	return text, true
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+
+	return append(list, s)
+}
@@ -0,0 +1,106 @@
+package parser //nolint:testpackage // tests verify internal helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+const changelogHTML = `<html><body>
+<h3><a class="anchor" name="recent-changes" href="#recent-changes"><i class="anchor-icon"></i></a>Recent changes</h3>
+<blockquote><p>Subscribe to <a href="https://t.me/botnews">@BotNews</a> to be the first to know.</p></blockquote>
+<h4><a class="anchor" name="march-31-2024" href="#march-31-2024"></a>March 31, 2024</h4>
+<p><strong>Bot API 7.2</strong></p>
+<ul>
+<li>Added the classes <a href="#businessconnection">BusinessConnection</a> and <a href="#businessintro">BusinessIntro</a>.</li>
+<li>Added the method <a href="#getbusinessconnection">getBusinessConnection</a>, returning a <a href="#businessconnection">BusinessConnection</a>.</li>
+<li>Added the field <em>business_connection_id</em> to the class <a href="#message">Message</a>.</li>
+<li>Supported business accounts:
+<ul>
+<li>Added the parameter <em>business_connection_id</em> to the methods <a href="#sendmessage">sendMessage</a> and <a href="#sendphoto">sendPhoto</a>.</li>
+</ul>
+</li>
+</ul>
+<h4><a class="anchor" name="february-16-2024" href="#february-16-2024"></a>February 16, 2024</h4>
+<p><strong>Bot API 7.1</strong></p>
+<ul>
+<li>Added the class <a href="#chatboostadded">ChatBoostAdded</a> and the field <em>boost_added</em> to the class <a href="#message">Message</a>. Added the method <a href="#getuserchatboosts">getUserChatBoosts</a>.</li>
+<li>Added the class <a href="#businessintro">BusinessIntro</a> for early adopters.</li>
+</ul>
+<h4>Sometime</h4>
+<p><strong>Bot API 7.0</strong></p>
+<p><a href="https://core.telegram.org/bots/api-changelog">See earlier changes »</a></p>
+<h3><a class="anchor" name="authorizing-your-bot"></a>Authorizing your bot</h3>
+<ul><li>Not a change.</li></ul>
+</body></html>`
+
+func TestParseChangelog(t *testing.T) {
+	releases := ParseChangelog(mustDoc(t, changelogHTML))
+
+	if len(releases) != 3 {
+		t.Fatalf("expected 3 releases, got %+v", releases)
+	}
+
+	latest := releases[0]
+	if latest.Version != "7.2" || latest.Date != "2024-03-31" || len(latest.Items) != 5 {
+		t.Fatalf("unexpected latest release %+v", latest)
+	}
+
+	want := []ChangeItem{
+		{
+			Text: "Added the classes [BusinessConnection](#/components/schemas/BusinessConnection) and " +
+				"[BusinessIntro](#/components/schemas/BusinessIntro).",
+			Refs:  []string{"BusinessConnection", "BusinessIntro"},
+			Added: []string{"BusinessConnection", "BusinessIntro"},
+		},
+		{
+			Text: "Added the method [getBusinessConnection](#/paths/~1getBusinessConnection/post), returning a " +
+				"[BusinessConnection](#/components/schemas/BusinessConnection).",
+			Refs:  []string{"getBusinessConnection", "BusinessConnection"},
+			Added: []string{"getBusinessConnection"},
+		},
+		{
+			Text: "Added the field *business_connection_id* to the class [Message](#/components/schemas/Message).",
+			Refs: []string{"Message"},
+		},
+		{Text: "Supported business accounts:"},
+		{
+			Text: "Added the parameter *business_connection_id* to the methods " +
+				"[sendMessage](#/paths/~1sendMessage/post) and [sendPhoto](#/paths/~1sendPhoto/post).",
+			Refs: []string{"sendMessage", "sendPhoto"},
+		},
+	}
+
+	for i := range want {
+		if !reflect.DeepEqual(latest.Items[i], want[i]) {
+			t.Errorf("item %d:\n got %#v\nwant %#v", i, latest.Items[i], want[i])
+		}
+	}
+
+	if got := releases[1].Items[0].Added; !reflect.DeepEqual(got, []string{"ChatBoostAdded", "getUserChatBoosts"}) {
+		t.Errorf("unexpected additions %v", got)
+	}
+
+	if releases[2].Date != "Sometime" || releases[2].Items != nil {
+		t.Errorf("unexpected oldest release %+v", releases[2])
+	}
+
+	since := Introduced(releases)
+	for name, version := range map[string]string{
+		"BusinessConnection":    "7.2",
+		"getBusinessConnection": "7.2",
+		"BusinessIntro":         "7.1",
+		"ChatBoostAdded":        "7.1",
+	} {
+		if since[name] != version {
+			t.Errorf("expected %s to be introduced in %s, got %q", name, version, since[name])
+		}
+	}
+
+	if _, ok := since["Message"]; ok {
+		t.Error("Message is only changed, not introduced")
+	}
+
+	if ParseChangelog(mustDoc(t, "<html><body><h3>Other</h3></body></html>")) != nil {
+		t.Error("expected nil changelog without the section")
+	}
+}
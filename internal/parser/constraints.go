@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// rangePattern matches "1-4096 characters", "2-10 items" or "1-100" and
+	// captures the word that follows to tell lengths from values.
+	rangePattern = regexp.MustCompile(`(?:^|[^\w.-])(\d+)\s*[-–]\s*(\d+)(?:\s+([a-zA-Z]+))?`)
+	// valuesBeforePattern and acceptedAfterPattern anchor a range without a
+	// unit to value wording, as in "Values between 1-100 are accepted".
+	valuesBeforePattern  = regexp.MustCompile(`(?i)\bvalues\s+between\s*$`)
+	acceptedAfterPattern = regexp.MustCompile(`(?i)^\s*(?:are\s+)?accepted\b`)
+	// betweenPattern matches "between 1 and 100".
+	betweenPattern = regexp.MustCompile(`(?i)\bbetween\s+(\d+)\s+and\s+(\d+)\b`)
+	// maxLengthPattern matches "up to 64 characters" or "at most 64 characters".
+	maxLengthPattern = regexp.MustCompile(`(?i)\b(?:up to|at most)\s+(\d+)\s+characters\b`)
+)
+
+// Constraints holds the bounds stated in a description.
+type Constraints struct {
+	// MinLength and MaxLength bound string length ("1-4096 characters").
+	MinLength, MaxLength *int
+	// Min and Max bound numeric values and array sizes ("Values between 1-100
+	// are accepted", "must include 2-10 items"). When a description lists
+	// several ranges, they span all of them.
+	Min, Max *int
+}
+
+// parseConstraints extracts the length and range bounds of a description.
+// Byte limits ("1-64 bytes") are skipped because they cannot be expressed as
+// a string length. Other ranges count only next to "characters", "items",
+// "Values between" or "accepted", so phone numbers, dates and similar
+// examples in the prose are not read as bounds.
+func parseConstraints(description string) Constraints {
+	var res Constraints
+
+	for _, idx := range rangePattern.FindAllStringSubmatchIndex(description, -1) {
+		lo, hi, ok := parseBounds(description[idx[2]:idx[3]], description[idx[4]:idx[5]])
+		if !ok {
+			continue
+		}
+
+		var unit string
+		if idx[6] >= 0 {
+			unit = strings.ToLower(description[idx[6]:idx[7]])
+		}
+
+		switch unit {
+		case "characters", "character":
+			res.MinLength, res.MaxLength = &lo, &hi
+		case "bytes", "byte":
+		case "items", "item":
+			res.widen(lo, hi)
+		default:
+			if valuesBeforePattern.MatchString(description[:idx[2]]) ||
+				acceptedAfterPattern.MatchString(description[idx[5]:]) {
+				res.widen(lo, hi)
+			}
+		}
+	}
+
+	for _, m := range betweenPattern.FindAllStringSubmatch(description, -1) {
+		if lo, hi, ok := parseBounds(m[1], m[2]); ok {
+			res.widen(lo, hi)
+		}
+	}
+
+	if res.MaxLength == nil {
+		if m := maxLengthPattern.FindStringSubmatch(description); m != nil {
+			if hi, err := strconv.Atoi(m[1]); err == nil {
+				res.MaxLength = &hi
+			}
+		}
+	}
+
+	return res
+}
+
+func (c *Constraints) widen(lo, hi int) {
+	if c.Min == nil || lo < *c.Min {
+		c.Min = &lo
+	}
+
+	if c.Max == nil || hi > *c.Max {
+		c.Max = &hi
+	}
+}
+
+func parseBounds(loRaw, hiRaw string) (int, int, bool) {
+	lo, err := strconv.Atoi(loRaw)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	hi, err := strconv.Atoi(hiRaw)
+	if err != nil || hi < lo {
+		return 0, 0, false
+	}
+
+	return lo, hi, true
+}
@@ -0,0 +1,118 @@
+package parser //nolint:testpackage // tests verify internal helpers
+
+import (
+	"testing"
+
+	"github.com/metalagman/tgbotspec/internal/openapi"
+)
+
+func intPtr(v int) *int { return &v }
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func TestParseConstraints(t *testing.T) { //nolint:funlen // table covers phrasings found in the docs
+	cases := []struct {
+		in   string
+		want Constraints
+	}{
+		{
+			in:   "Text of the message to be sent, 1-4096 characters after entities parsing",
+			want: Constraints{MinLength: intPtr(1), MaxLength: intPtr(4096)},
+		},
+		{
+			in:   "Optional. Photo caption, 0-1024 characters after entities parsing",
+			want: Constraints{MinLength: intPtr(0), MaxLength: intPtr(1024)},
+		},
+		{
+			in:   "Limits the number of updates to be retrieved. Values between 1-100 are accepted. Defaults to 100.",
+			want: Constraints{Min: intPtr(1), Max: intPtr(100)},
+		},
+		{
+			in:   "Number of seconds, between 5 and 600",
+			want: Constraints{Min: intPtr(5), Max: intPtr(600)},
+		},
+		{
+			in:   "A JSON-serialized array describing messages to be sent, must include 2-10 items",
+			want: Constraints{Min: intPtr(2), Max: intPtr(10)},
+		},
+		{
+			in:   "Values between 1-6 are accepted for “🎲”, 1-64 are accepted for “🎰”",
+			want: Constraints{Min: intPtr(1), Max: intPtr(64)},
+		},
+		{
+			in:   "Number of days, 7-30 accepted",
+			want: Constraints{Min: intPtr(7), Max: intPtr(30)},
+		},
+		{
+			in: "Value of the dice, 1-6 for “🎲” base emoji, 1-5 for “🏀” and 1-64 for “🎰” base emoji",
+		},
+		{
+			in: "Phone number of the user, e.g. +1-212-555-0123",
+		},
+		{
+			in: "Date of the event, for example 12-31 or 1-15",
+		},
+		{
+			in: "Opening hours of the business, e.g. 9-17",
+		},
+		{
+			in:   "Optional. Text of the button, up to 64 characters",
+			want: Constraints{MaxLength: intPtr(64)},
+		},
+		{
+			in: "Data to be sent in a callback query to the bot when the button is pressed, 1-64 bytes",
+		},
+		{
+			in: "Two-letter ISO 3166-1 alpha-2 country code",
+		},
+	}
+
+	for _, tc := range cases {
+		got := parseConstraints(tc.in)
+		if !equalIntPtr(got.MinLength, tc.want.MinLength) || !equalIntPtr(got.MaxLength, tc.want.MaxLength) ||
+			!equalIntPtr(got.Min, tc.want.Min) || !equalIntPtr(got.Max, tc.want.Max) {
+			t.Fatalf("parseConstraints(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestConstraintsApply(t *testing.T) {
+	ann := Annotations{Constraints: Constraints{
+		MinLength: intPtr(1), MaxLength: intPtr(4096), Min: intPtr(2), Max: intPtr(10),
+	}}
+
+	str := ann.Apply(&openapi.TypeSpec{Type: "string"})
+	if *str.MinLength != 1 || *str.MaxLength != 4096 || str.Minimum != nil {
+		t.Fatalf("unexpected string bounds: %#v", str)
+	}
+
+	num := ann.Apply(&openapi.TypeSpec{Type: "integer"})
+	if *num.Minimum != 2 || *num.Maximum != 10 || num.MaxLength != nil {
+		t.Fatalf("unexpected integer bounds: %#v", num)
+	}
+
+	arr := ann.Apply(&openapi.TypeSpec{Type: "array", Items: &openapi.TypeSpec{Type: "string"}})
+	if *arr.MinItems != 2 || *arr.MaxItems != 10 || arr.Items.MaxLength != nil {
+		t.Fatalf("unexpected array bounds: %#v", arr)
+	}
+
+	for _, spec := range []*openapi.TypeSpec{
+		{Type: "string", Format: "binary"},
+		{OneOf: []openapi.TypeSpec{{Type: "integer"}, {Type: "string"}}},
+	} {
+		if got := ann.Apply(spec); got.MaxLength != nil || got.Maximum != nil {
+			t.Fatalf("expected no bounds on %#v", got)
+		}
+	}
+
+	zero := Annotations{Constraints: Constraints{MinLength: intPtr(0), MaxLength: intPtr(1024)}}
+	if got := zero.Apply(&openapi.TypeSpec{Type: "string"}); got.MinLength != nil || *got.MaxLength != 1024 {
+		t.Fatalf("expected zero minimum length to be omitted, got %#v", got)
+	}
+}
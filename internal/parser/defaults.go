@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/metalagman/tgbotspec/internal/openapi"
+)
+
+// defaultPattern matches "Defaults to 100", "defaults to True" and
+// "defaults to “regular”", capturing the quoted or bare value.
+var defaultPattern = regexp.MustCompile(`(?i:\bdefaults\s+to)\s+([“"][^”"]*[”"]|[^\s,;]+)`)
+
+// parseDefault returns the raw value of a "Defaults to X" clause, keeping the
+// quotes of quoted values so coerceDefault can tell strings from prose.
+func parseDefault(description string) string {
+	m := defaultPattern.FindStringSubmatch(description)
+	if m == nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(m[1], ".")
+}
+
+// coerceDefault converts a raw default to the type of spec. It returns nil
+// when the value does not fit, e.g. "defaults to the current chat" on a
+// string or "defaults to 1 hour" on an integer.
+func coerceDefault(raw string, spec *openapi.TypeSpec) interface{} {
+	if raw == "" {
+		return nil
+	}
+
+	switch spec.Type {
+	case "integer":
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return v
+		}
+	case "number":
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(strings.ToLower(raw)); err == nil {
+			return v
+		}
+	case "string":
+		if spec.Format != "" {
+			return nil
+		}
+
+		for _, quotes := range [][2]string{{"“", "”"}, {`"`, `"`}} {
+			if v, ok := strings.CutPrefix(raw, quotes[0]); ok {
+				if v, ok = strings.CutSuffix(v, quotes[1]); ok && v != "" {
+					return v
+				}
+			}
+		}
+	}
+
+	return nil
+}
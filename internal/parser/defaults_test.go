@@ -0,0 +1,58 @@
+package parser //nolint:testpackage // tests verify internal helpers
+
+import (
+	"testing"
+
+	"github.com/metalagman/tgbotspec/internal/openapi"
+)
+
+func TestParseDefault(t *testing.T) {
+	cases := map[string]string{
+		"Limits the number of updates to be retrieved. Values between 1-100 are accepted. Defaults to 100.": "100",
+		"Timeout in seconds for long polling. Defaults to 0, i.e. usual short polling.":                     "0",
+		"True, if the poll needs to be anonymous, defaults to True":                                         "True",
+		"Poll type, “quiz” or “regular”, defaults to “regular”":                                             "“regular”",
+		"Identifier of the chat": "",
+	}
+
+	for in, want := range cases {
+		if got := parseDefault(in); got != want {
+			t.Fatalf("parseDefault(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCoerceDefault(t *testing.T) {
+	cases := []struct {
+		raw  string
+		spec openapi.TypeSpec
+		want interface{}
+	}{
+		{"100", openapi.TypeSpec{Type: "integer"}, int64(100)},
+		{"0.5", openapi.TypeSpec{Type: "number"}, 0.5},
+		{"True", openapi.TypeSpec{Type: "boolean"}, true},
+		{"“regular”", openapi.TypeSpec{Type: "string"}, "regular"},
+		{`"🎲"`, openapi.TypeSpec{Type: "string"}, "🎲"},
+		{"the", openapi.TypeSpec{Type: "string"}, nil},
+		{"the", openapi.TypeSpec{Type: "integer"}, nil},
+		{"100", openapi.TypeSpec{Type: "array"}, nil},
+		{"“x”", openapi.TypeSpec{Type: "string", Format: "binary"}, nil},
+		{"", openapi.TypeSpec{Type: "integer"}, nil},
+	}
+
+	for _, tc := range cases {
+		if got := coerceDefault(tc.raw, &tc.spec); got != tc.want {
+			t.Fatalf("coerceDefault(%q, %s) = %#v, want %#v", tc.raw, tc.spec.Type, got, tc.want)
+		}
+	}
+
+	spec := ParseAnnotations("Defaults to 100").Apply(&openapi.TypeSpec{Type: "integer"})
+	if spec.Default != int64(100) {
+		t.Fatalf("expected default from annotations, got %#v", spec.Default)
+	}
+
+	literal := ParseAnnotations("defaults to False").Apply(&openapi.TypeSpec{Type: "boolean", Default: true})
+	if literal.Default != true {
+		t.Fatalf("expected existing default to be kept, got %#v", literal.Default)
+	}
+}
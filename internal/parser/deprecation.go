@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// deprecatedPattern matches the wording Telegram uses for obsolete
+	// fields, parameters and methods. Backward compatibility only counts when
+	// something is kept for it: "For backward compatibility, the field
+	// contains a fake sender user" describes a live field.
+	deprecatedPattern = regexp.MustCompile(
+		`(?i)\bdeprecated\b|\bno longer supported\b|\bkept\s+(?:only\s+)?for backward compatibility\b`)
+	// sentenceEnd splits a description into sentences.
+	sentenceEnd = regexp.MustCompile(`[.!?](?:\s+|$)`)
+	// replacementPatterns capture the name of the replacement, e.g. "Use
+	// reply_parameters instead" or "deprecated in favor of the field thumbnail".
+	replacementPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i:\buse\s+(?:the\s+)?(?:field|parameter|method)?\s*)` +
+			`([A-Za-z_][A-Za-z0-9_]*)(?i:\s+instead\b)`),
+		regexp.MustCompile(`(?i:\b(?:in favou?r of|replaced (?:by|with))\s+(?:the\s+)?(?:field|parameter|method)?\s*)` +
+			`([A-Za-z_][A-Za-z0-9_]*)`),
+	}
+)
+
+// Deprecation marks an obsolete field, parameter or method.
+type Deprecation struct {
+	Deprecated bool
+	// ReplacedBy names the field, parameter or method to use instead, when the
+	// docs mention one.
+	ReplacedBy string
+}
+
+// parseDeprecation detects deprecation wording such as "Deprecated. Use X
+// instead" or "kept only for backward compatibility". Replacement wording alone
+// ("The old text will be replaced by the new text") is not a deprecation: the
+// replacement is only taken from the sentence with the deprecation wording or
+// the one right after it.
+func parseDeprecation(description string) Deprecation {
+	var res Deprecation
+
+	sentences := sentenceEnd.Split(description, -1)
+
+	for i, sentence := range sentences {
+		if !deprecatedPattern.MatchString(sentence) {
+			continue
+		}
+
+		res.Deprecated = true
+
+		for _, candidate := range sentences[i:min(i+2, len(sentences))] {
+			if name := replacement(candidate); name != "" {
+				res.ReplacedBy = name
+
+				return res
+			}
+		}
+	}
+
+	return res
+}
+
+// replacement returns the name a sentence points to as the replacement, e.g.
+// reply_parameters in "Use reply_parameters instead".
+func replacement(sentence string) string {
+	for _, re := range replacementPatterns {
+		if m := re.FindStringSubmatch(sentence); m != nil && !isFillerWord(m[1]) {
+			return m[1]
+		}
+	}
+
+	return ""
+}
+
+// ResolveReplacements clears the ReplacedBy hints that do not name a parsed
+// type or method, or a field or parameter of the same type or method, so a
+// phrase such as "replaced by the new text" does not become a hint. Every
+// cleared hint is reported as a warning.
+func ResolveReplacements(types []TypeDef, methods []MethodDef, report *Report) {
+	known := make(map[string]struct{}, len(types)+len(methods))
+	for _, t := range types {
+		known[t.Name] = struct{}{}
+	}
+
+	for _, m := range methods {
+		known[m.Name] = struct{}{}
+	}
+
+	check := func(location string, d *Deprecation, siblings map[string]struct{}) {
+		if d.ReplacedBy == "" {
+			return
+		}
+
+		_, isName := known[d.ReplacedBy]
+		_, isSibling := siblings[d.ReplacedBy]
+
+		if !isName && !isSibling {
+			report.Warnf(location, d.ReplacedBy, "deprecation replacement %s is not a known name", d.ReplacedBy)
+			d.ReplacedBy = ""
+		}
+	}
+
+	for i := range types {
+		t := &types[i]
+		fields := make(map[string]struct{}, len(t.Fields))
+
+		for _, f := range t.Fields {
+			fields[f.Name] = struct{}{}
+		}
+
+		for j := range t.Fields {
+			check(t.Name+"."+t.Fields[j].Name, &t.Fields[j].Deprecation, fields)
+		}
+	}
+
+	for i := range methods {
+		m := &methods[i]
+		params := make(map[string]struct{}, len(m.Params))
+
+		for _, p := range m.Params {
+			params[p.Name] = struct{}{}
+		}
+
+		check(m.Name, &m.Deprecation, nil)
+
+		for j := range m.Params {
+			check(m.Name+"."+m.Params[j].Name, &m.Params[j].Deprecation, params)
+		}
+	}
+}
+
+// isFillerWord filters captures like "Use this instead" that do not name an
+// identifier.
+func isFillerWord(s string) bool {
+	switch strings.ToLower(s) {
+	case "this", "that", "it", "them", "one", "a", "an", "the":
+		return true
+	}
+
+	return false
+}
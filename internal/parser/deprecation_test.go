@@ -0,0 +1,139 @@
+package parser //nolint:testpackage // tests verify internal helpers
+
+import (
+	"testing"
+
+	"github.com/metalagman/tgbotspec/internal/openapi"
+)
+
+func TestParseDeprecation(t *testing.T) {
+	cases := map[string]Deprecation{
+		"Optional. Deprecated. Use reply_parameters instead":                      {Deprecated: true, ReplacedBy: "reply_parameters"},
+		"Deprecated in favor of the field thumbnail":                              {Deprecated: true, ReplacedBy: "thumbnail"},
+		"Deprecated, use the parameter link_preview_options instead":              {Deprecated: true, ReplacedBy: "link_preview_options"},
+		"Optional. Kept for backward compatibility":                               {Deprecated: true},
+		"Optional. Kept only for backward compatibility":                          {Deprecated: true},
+		"No longer supported. Use the method getChat instead. Returns True.":      {Deprecated: true, ReplacedBy: "getChat"},
+		"Deprecated. Returns True. Use getChat instead.":                          {Deprecated: true},
+		"Optional. Use the parameter link_preview_options instead":                {},
+		"New text of the message. The old text will be replaced by the new text.": {},
+		"Use this method to send text messages. Returns the sent Message.":        {},
+		"Optional. Pass True if the message should be sent even if not found.":    {},
+		"Optional. If the sticker can't be found, use this instead":               {},
+	}
+
+	for in, want := range cases {
+		if got := parseDeprecation(in); got != want {
+			t.Fatalf("parseDeprecation(%q) = %+v, want %+v", in, got, want)
+		}
+	}
+}
+
+func TestParseDeprecationBackwardCompatibleField(t *testing.T) {
+	// Message.from and Message.sender_chat are live fields.
+	for _, description := range []string{
+		"Optional. Sender of the message; may be empty for messages sent to channels. For backward compatibility, " +
+			"if the message was sent on behalf of a chat, the field contains a fake sender user in non-channel chats",
+		"Optional. Sender of the message when sent on behalf of a chat. For example, the supergroup itself for " +
+			"messages sent by its anonymous administrators or a linked channel for messages automatically forwarded " +
+			"to the channel's discussion group. For backward compatibility, if the message was sent on behalf of a " +
+			"chat, the field from contains a fake sender user in non-channel chats.",
+	} {
+		if got := parseDeprecation(description); got.Deprecated {
+			t.Fatalf("expected %q not to be deprecated", description)
+		}
+	}
+}
+
+func TestResolveReplacements(t *testing.T) {
+	types := []TypeDef{{
+		Name: "Message",
+		Fields: []TypeFieldDef{
+			{Name: "thumb", Annotations: Annotations{Deprecation: Deprecation{Deprecated: true, ReplacedBy: "thumbnail"}}},
+			{Name: "thumbnail"},
+			{Name: "old", Annotations: Annotations{Deprecation: Deprecation{Deprecated: true, ReplacedBy: "new"}}},
+		},
+	}}
+	methods := []MethodDef{
+		{Name: "kickChatMember", Deprecation: Deprecation{Deprecated: true, ReplacedBy: "banChatMember"}},
+		{Name: "banChatMember"},
+		{Name: "getChatMembersCount", Deprecation: Deprecation{Deprecated: true, ReplacedBy: "getChatMemberCount"}},
+	}
+
+	report := &Report{}
+	ResolveReplacements(types, methods, report)
+
+	if got := types[0].Fields[0].ReplacedBy; got != "thumbnail" {
+		t.Errorf("expected the sibling field to be kept, got %q", got)
+	}
+
+	if got := types[0].Fields[2].Deprecation; got != (Deprecation{Deprecated: true}) {
+		t.Errorf("expected the unknown replacement to be cleared, got %+v", got)
+	}
+
+	if methods[0].ReplacedBy != "banChatMember" || methods[2].ReplacedBy != "" {
+		t.Errorf("unexpected method replacements %+v %+v", methods[0].Deprecation, methods[2].Deprecation)
+	}
+
+	if len(report.Diagnostics) != 2 || report.Diagnostics[0].Anchor != "Message.old" {
+		t.Fatalf("expected the cleared replacements to be reported, got %+v", report.Diagnostics)
+	}
+}
+
+func TestDeprecationApply(t *testing.T) {
+	spec := ParseAnnotations("Deprecated. Use reply_parameters instead").Apply(&openapi.TypeSpec{Type: "integer"})
+	if !spec.Deprecated || spec.Extensions["x-replaced-by"] != "reply_parameters" {
+		t.Fatalf("expected deprecated schema with replacement, got %#v", spec)
+	}
+
+	plain := ParseAnnotations("Optional. Kept for backward compatibility").Apply(&openapi.TypeSpec{Type: "string"})
+	if !plain.Deprecated || plain.Extensions != nil {
+		t.Fatalf("expected deprecated schema without replacement, got %#v", plain)
+	}
+}
+
+func TestParseMethodDeprecated(t *testing.T) {
+	doc := mustDoc(t, `
+		<html><body>
+		<h4><a class="anchor" name="kickchatmember"></a>kickChatMember</h4>
+		<p>Deprecated. Use <a href="#banchatmember">banChatMember</a> instead. Returns True on success.</p>
+		</body></html>
+	`)
+
+	method, err := ParseMethod(doc, "kickchatmember")
+	if err != nil {
+		t.Fatalf("ParseMethod returned error: %v", err)
+	}
+
+	if !method.Deprecated || method.ReplacedBy != "banChatMember" {
+		t.Fatalf("expected deprecated method replaced by banChatMember, got %+v", method.Deprecation)
+	}
+}
+
+func TestParseMethodReplacementWithoutDeprecation(t *testing.T) {
+	doc := mustDoc(t, `
+		<html><body>
+		<h4><a class="anchor" name="editmessagetext"></a>editMessageText</h4>
+		<p>Use this method to edit text messages. On success, True is returned.</p>
+		<table class="table">
+		  <tbody>
+			<tr>
+			  <td>text</td>
+			  <td>String</td>
+			  <td>Yes</td>
+			  <td>New text of the message. The old text will be replaced by the new text.</td>
+			</tr>
+		  </tbody>
+		</table>
+		</body></html>
+	`)
+
+	method, err := ParseMethod(doc, "editmessagetext")
+	if err != nil {
+		t.Fatalf("ParseMethod returned error: %v", err)
+	}
+
+	if got, ok := method.Param("text"); !ok || got.Deprecation != (Deprecation{}) {
+		t.Fatalf("expected text not to be deprecated, got %+v", got)
+	}
+}
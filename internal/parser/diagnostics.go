@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Severity classifies a Diagnostic.
+type Severity string
+
+const (
+	// SeverityWarning marks a heuristic that degraded gracefully.
+	SeverityWarning Severity = "warning"
+	// SeverityError marks content missing from or wrong in the output.
+	SeverityError Severity = "error"
+)
+
+// Diagnostic describes a problem found while parsing the documentation.
+type Diagnostic struct {
+	// Anchor locates the problem: a documentation anchor such as
+	// "sendmessage", or a "Type.field" / "method.param" path.
+	Anchor   string   `json:"anchor,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Text is the offending documentation text, if any.
+	Text string `json:"text,omitempty"`
+}
+
+// Report collects diagnostics. A nil *Report discards everything so callers
+// do not need to check whether reporting is enabled.
+type Report struct {
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Add records a diagnostic.
+func (r *Report) Add(d Diagnostic) {
+	if r == nil {
+		return
+	}
+
+	r.Diagnostics = append(r.Diagnostics, d)
+}
+
+// Errorf records an error for the given anchor and offending text.
+func (r *Report) Errorf(anchor, text, format string, args ...interface{}) {
+	r.Add(Diagnostic{Anchor: anchor, Severity: SeverityError, Message: fmt.Sprintf(format, args...), Text: text})
+}
+
+// Warnf records a warning for the given anchor and offending text.
+func (r *Report) Warnf(anchor, text, format string, args ...interface{}) {
+	r.Add(Diagnostic{Anchor: anchor, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...), Text: text})
+}
+
+// Errors returns the number of error diagnostics.
+func (r *Report) Errors() int {
+	if r == nil {
+		return 0
+	}
+
+	n := 0
+
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Only returns a report holding the diagnostics of the given severity.
+func (r *Report) Only(severity Severity) *Report {
+	if r == nil {
+		return nil
+	}
+
+	res := &Report{}
+
+	for _, d := range r.Diagnostics {
+		if d.Severity == severity {
+			res.Add(d)
+		}
+	}
+
+	return res
+}
+
+// WriteText writes one line per diagnostic:
+//
+//	error: sendmessage: method return type not parsed: "Use this method..."
+func (r *Report) WriteText(w io.Writer) error {
+	if r == nil {
+		return nil
+	}
+
+	for _, d := range r.Diagnostics {
+		parts := []string{string(d.Severity)}
+		if d.Anchor != "" {
+			parts = append(parts, d.Anchor)
+		}
+
+		parts = append(parts, d.Message)
+
+		line := strings.Join(parts, ": ")
+		if d.Text != "" {
+			line += fmt.Sprintf(": %q", d.Text)
+		}
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("write diagnostics: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON writes the report as an indented JSON document.
+func (r *Report) WriteJSON(w io.Writer) error {
+	if r == nil {
+		r = &Report{}
+	}
+
+	if r.Diagnostics == nil {
+		r = &Report{Diagnostics: []Diagnostic{}}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("write diagnostics: %w", err)
+	}
+
+	return nil
+}
+
+// ParseError is returned by ParseType and ParseMethod. It carries the anchor
+// and the documentation text that could not be understood.
+type ParseError struct {
+	Anchor string
+	Name   string
+	Text   string
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("parse %s (%s): %v", e.Name, e.Anchor, e.Err)
+	}
+
+	return fmt.Sprintf("parse anchor %s: %v", e.Anchor, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,81 @@
+package parser //nolint:testpackage // tests verify internal helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	report := &Report{}
+	report.Errorf("sendmessage", "Use this method.", "method return type not parsed")
+	report.Warnf("", "", "no navigation found")
+
+	if report.Errors() != 1 || len(report.Diagnostics) != 2 {
+		t.Fatalf("unexpected report: %#v", report)
+	}
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+
+	want := "error: sendmessage: method return type not parsed: \"Use this method.\"\nwarning: no navigation found\n"
+	if text.String() != want {
+		t.Fatalf("unexpected text %q", text.String())
+	}
+
+	if errs := report.Only(SeverityError); len(errs.Diagnostics) != 1 || errs.Diagnostics[0] != report.Diagnostics[0] {
+		t.Fatalf("unexpected error-only report: %#v", errs)
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteJSON(&out); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if decoded.Diagnostics[0] != report.Diagnostics[0] {
+		t.Fatalf("unexpected JSON round trip: %#v", decoded)
+	}
+}
+
+func TestNilReport(t *testing.T) {
+	var report *Report
+
+	report.Errorf("a", "", "ignored")
+
+	if report.Errors() != 0 || report.Only(SeverityError) != nil {
+		t.Fatal("expected nil report to discard diagnostics")
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteJSON(&out); err != nil || out.String() != "{\n  \"diagnostics\": []\n}\n" {
+		t.Fatalf("unexpected empty JSON %q (err %v)", out.String(), err)
+	}
+}
+
+func TestParseErrorFromParseMethod(t *testing.T) {
+	doc := mustDoc(t, `
+		<html><body>
+		<h4><a class="anchor" name="brokenmethod"></a>brokenMethod</h4>
+		<p>Does something mysterious.</p>
+		</body></html>
+	`)
+
+	_, err := ParseMethod(doc, "brokenmethod")
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !errors.Is(err, ErrReturnTypeNotParsed) {
+		t.Fatalf("expected ParseError wrapping ErrReturnTypeNotParsed, got %v", err)
+	}
+
+	if parseErr.Anchor != "brokenmethod" || parseErr.Text != "Does something mysterious." {
+		t.Fatalf("unexpected parse error details: %#v", parseErr)
+	}
+}
@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// minEnumValues is the smallest number of quoted alternatives treated as a
+// closed value set; a single quoted word is usually just an example.
+const minEnumValues = 2
+
+// enumTriggers are the phrasings that introduce a list of allowed values.
+var enumTriggers = []string{"one of", "can be", "either", "must be"}
+
+// constantPatterns match fields documented to hold exactly one value, such as
+// "always “creator”" or "Type of the result, must be photo". The bare form is
+// only accepted as the last words of a sentence to skip prose like "must be
+// sent by the user".
+var constantPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i:\balways)\s+[“"]([^\s“”"]+)[”"]`),
+	regexp.MustCompile(`(?i:,\s*must\s+be)\s+(?:[“"]([^\s“”"]+)[”"]|([a-z][a-z0-9_]*))\s*\.?$`),
+}
+
+// parseEnum recognises descriptions such as
+//
+//	Type of the chat, can be either “private”, “group”, “supergroup” or “channel”
+//
+// and returns the quoted alternatives. Only the sentence containing the
+// trigger phrase is considered, so later examples like “🎲” in "Defaults to
+// “🎲”" do not leak into the set.
+func parseEnum(description string) []string {
+	for _, sentence := range splitDescriptionSentences(description) {
+		lower := strings.ToLower(sentence)
+
+		start := -1
+
+		for _, trigger := range enumTriggers {
+			if idx := strings.Index(lower, trigger); idx != -1 && (start == -1 || idx < start) {
+				start = idx + len(trigger)
+			}
+		}
+
+		if start == -1 {
+			continue
+		}
+
+		if values := enumValues(quotedValues(sentence[start:])); len(values) >= minEnumValues {
+			return values
+		}
+	}
+
+	return nil
+}
+
+// parseConstant returns the single value of a constant field, e.g. the
+// discriminator of BotCommandScopeDefault or ChatMemberOwner, or an empty
+// string when the description does not pin the value.
+func parseConstant(description string) string {
+	for _, sentence := range splitDescriptionSentences(description) {
+		for _, re := range constantPatterns {
+			m := re.FindStringSubmatch(sentence)
+			if m == nil {
+				continue
+			}
+
+			for _, v := range m[1:] {
+				if v != "" {
+					return v
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// enumValues deduplicates values preserving their order. Any value containing
+// whitespace means the quotes hold prose rather than identifiers, so the whole
+// list is rejected.
+func enumValues(raw []string) []string {
+	seen := make(map[string]struct{}, len(raw))
+	values := make([]string, 0, len(raw))
+
+	for _, v := range raw {
+		if v == "" || strings.IndexFunc(v, unicode.IsSpace) != -1 {
+			return nil
+		}
+
+		if _, ok := seen[v]; ok {
+			continue
+		}
+
+		seen[v] = struct{}{}
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// quotedValues returns the contents of “curly” and "straight" quotes in s.
+func quotedValues(s string) []string {
+	var (
+		values  []string
+		current strings.Builder
+		closing rune
+	)
+
+	for _, r := range s {
+		switch {
+		case closing == 0 && r == '“':
+			closing = '”'
+		case closing == 0 && r == '"':
+			closing = '"'
+		case closing != 0 && r == closing:
+			values = append(values, current.String())
+			current.Reset()
+
+			closing = 0
+		case closing != 0:
+			current.WriteRune(r)
+		}
+	}
+
+	return values
+}
+
+// splitDescriptionSentences splits a description into sentences. Unlike
+// splitIntoSentences it only breaks on a terminator followed by whitespace or
+// the end of text and never inside quotes, so values such as “https://t.me”
+// or “.” survive intact.
+func splitDescriptionSentences(text string) []string {
+	runes := []rune(strings.TrimSpace(text))
+
+	var (
+		sentences []string
+		start     int
+		closing   rune
+	)
+
+	for i, r := range runes {
+		switch {
+		case closing == 0 && r == '“':
+			closing = '”'
+
+			continue
+		case closing == 0 && r == '"':
+			closing = '"'
+
+			continue
+		case closing != 0:
+			if r == closing {
+				closing = 0
+			}
+
+			continue
+		}
+
+		if r != '.' && r != '!' && r != '?' {
+			continue
+		}
+
+		if i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			continue
+		}
+
+		if s := strings.TrimSpace(string(runes[start : i+1])); s != "" {
+			sentences = append(sentences, s)
+		}
+
+		start = i + 1
+	}
+
+	if tail := strings.TrimSpace(string(runes[start:])); tail != "" {
+		sentences = append(sentences, tail)
+	}
+
+	return sentences
+}
@@ -0,0 +1,130 @@
+package parser //nolint:testpackage // tests verify internal helpers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/metalagman/tgbotspec/internal/openapi"
+)
+
+func TestParseEnum(t *testing.T) { //nolint:funlen // table covers phrasings found in the docs
+	cases := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{
+			name: "can be either",
+			in:   "Type of the chat, can be either “private”, “group”, “supergroup” or “channel”",
+			want: []string{"private", "group", "supergroup", "channel"},
+		},
+		{
+			name: "currently one of",
+			in: "Type of the sticker, currently one of “regular”, “mask”, “custom_emoji”. " +
+				"The type of the sticker is independent from its format.",
+			want: []string{"regular", "mask", "custom_emoji"},
+		},
+		{
+			name: "parenthesised notes",
+			in: "Type of the entity. Currently, can be “mention” (@username), “hashtag” (#hashtag), " +
+				"“url” (https://telegram.org), “text_link” (for clickable text URLs)",
+			want: []string{"mention", "hashtag", "url", "text_link"},
+		},
+		{
+			name: "only the trigger sentence",
+			in: "Emoji on which the dice throw animation is based. Currently, must be one of “🎲”, “🎯” or “🏀”. " +
+				"Dice can have values 1-6 for “🎲” and “🎯”, and values 1-5 for “🏀”. Defaults to “🎲”",
+			want: []string{"🎲", "🎯", "🏀"},
+		},
+		{
+			name: "straight quotes",
+			in:   `Reaction emoji. Currently, it can be one of "👍", "👎", "❤"`,
+			want: []string{"👍", "👎", "❤"},
+		},
+		{
+			name: "duplicates",
+			in:   "Optional. Can be either “sender”, “private” or “private”",
+			want: []string{"sender", "private"},
+		},
+		{
+			name: "single value",
+			in:   "Optional. For “pre” only, the programming language of the entity text",
+		},
+		{
+			name: "no trigger",
+			in:   "Optional. Special entities like “bold” and “italic” that appear in the text",
+		},
+		{
+			name: "prose in quotes",
+			in:   "Text of the button, can be “Open app” or “Close”",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseEnum(tc.in); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("parseEnum(%q) = %#v, want %#v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplitDescriptionSentences(t *testing.T) {
+	got := splitDescriptionSentences("Can be “a.b” or “.”. See https://t.me/x. Done")
+	want := []string{"Can be “a.b” or “.”.", "See https://t.me/x.", "Done"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected sentences: %#v", got)
+	}
+}
+
+func TestAnnotationsApply(t *testing.T) {
+	ann := ParseAnnotations("Type of the chat, can be either “private” or “group”")
+
+	spec := ann.Apply(&openapi.TypeSpec{Type: "string"})
+	if !reflect.DeepEqual(spec.Enum, []interface{}{"private", "group"}) {
+		t.Fatalf("expected enum on string schema, got %#v", spec.Enum)
+	}
+
+	for _, other := range []*openapi.TypeSpec{
+		{Type: "integer"},
+		{Type: "string", Format: "binary"},
+		{Type: "array", Items: &openapi.TypeSpec{Type: "string"}},
+	} {
+		if got := ann.Apply(other); got.Enum != nil {
+			t.Fatalf("expected no enum on %#v", got)
+		}
+	}
+
+	if ann.Apply(nil) != nil {
+		t.Fatal("expected nil spec to stay nil")
+	}
+}
+
+func TestParseConstant(t *testing.T) {
+	cases := map[string]string{
+		"The member's status in the chat, always “creator”":                "creator",
+		`Type of the reaction, always "emoji"`:                             "emoji",
+		"Scope type, must be default":                                      "default",
+		"Type of the result, must be photo.":                               "photo",
+		"Error source, must be “data”":                                     "data",
+		"Text of the message, must be sent by the user":                    "",
+		"Type of the chat, can be either “private” or “group”":             "",
+		"Identifier of the message. Must be positive and fit into 32 bits": "",
+	}
+
+	for in, want := range cases {
+		if got := parseConstant(in); got != want {
+			t.Fatalf("parseConstant(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	ann := ParseAnnotations("Type of the result, must be photo")
+	if v, ok := ann.Constant(); !ok || v != "photo" {
+		t.Fatalf("expected constant annotation, got %#v", ann)
+	}
+
+	if _, ok := ParseAnnotations("Kind, one of “a” or “b”").Constant(); ok {
+		t.Fatal("expected multi-value enum not to be a constant")
+	}
+}
@@ -0,0 +1,212 @@
+package parser
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DocsURL is the location of the documentation page; relative and unresolved
+// in-page links in descriptions are made absolute against it.
+const DocsURL = "https://core.telegram.org/bots/api"
+
+const codeFence = "```"
+
+// internalLinkPattern matches the link targets linkTarget gives types and
+// methods.
+var internalLinkPattern = regexp.MustCompile(`\]\(#/(?:components/schemas/(\w+)|paths/~1(\w+)/post)\)`)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+)
+
+// Markdown converts the inline HTML of a description into CommonMark. Bold,
+// italic and code formatting is kept, emoji images become their alt text and
+// links are rewritten by linkTarget so they stay navigable in the generated
+// spec. Heuristics should keep working on Selection.Text, not on this output.
+func Markdown(sel *goquery.Selection) string {
+	var b strings.Builder
+
+	writeMarkdown(&b, sel)
+
+	lines := strings.Split(b.String(), "\n")
+	res := make([]string, 0, len(lines))
+	inCode := false
+
+	for _, line := range lines {
+		if line == codeFence {
+			inCode = !inCode
+		}
+
+		if !inCode {
+			line = strings.TrimSpace(line)
+		}
+
+		res = append(res, line)
+	}
+
+	return strings.TrimSpace(strings.Join(res, "\n"))
+}
+
+//nolint:cyclop // one branch per supported element
+func writeMarkdown(b *strings.Builder, sel *goquery.Selection) {
+	sel.Contents().Each(func(_ int, node *goquery.Selection) {
+		switch goquery.NodeName(node) {
+		case "#text":
+			b.WriteString(markdownEscaper.Replace(collapseSpace(node.Text())))
+		case "strong", "b":
+			writeWrapped(b, "**", node)
+		case "em", "i":
+			writeWrapped(b, "*", node)
+		case "code":
+			if code := node.Text(); code != "" {
+				b.WriteString("`" + code + "`")
+			}
+		case "pre":
+			b.WriteString("\n" + codeFence + "\n" + strings.Trim(node.Text(), "\n") + "\n" + codeFence + "\n")
+		case "a":
+			writeLink(b, node)
+		case "img":
+			alt, _ := node.Attr("alt")
+			b.WriteString(alt)
+		case "br":
+			b.WriteString("\n")
+		default:
+			writeMarkdown(b, node)
+		}
+	})
+}
+
+// writeWrapped surrounds the content of node with marker, keeping surrounding
+// whitespace outside of it as CommonMark requires.
+func writeWrapped(b *strings.Builder, marker string, node *goquery.Selection) {
+	var inner strings.Builder
+
+	writeMarkdown(&inner, node)
+
+	text := inner.String()
+
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		b.WriteString(text)
+
+		return
+	}
+
+	if strings.HasPrefix(text, " ") {
+		b.WriteString(" ")
+	}
+
+	b.WriteString(marker + trimmed + marker)
+
+	if strings.HasSuffix(text, " ") {
+		b.WriteString(" ")
+	}
+}
+
+func writeLink(b *strings.Builder, node *goquery.Selection) {
+	var inner strings.Builder
+
+	writeMarkdown(&inner, node)
+
+	text := strings.TrimSpace(inner.String())
+	href, _ := node.Attr("href")
+
+	target := linkTarget(href, strings.TrimSpace(node.Text()))
+	if target == "" || text == "" {
+		b.WriteString(inner.String())
+
+		return
+	}
+
+	b.WriteString("[" + text + "](" + target + ")")
+}
+
+// linkTarget rewrites a documentation link. In-page anchors whose link text is
+// the name of a type or method (e.g. <a href="#message">Message</a>) point to
+// the component schema or operation, until ResolveLinks checks them against
+// what is emitted; every other link is made absolute.
+func linkTarget(href, text string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+
+	if anchor, ok := strings.CutPrefix(href, "#"); ok && anchor != "" && strings.EqualFold(anchor, text) &&
+		containsExactlyOneWord(text) {
+		if isFirstLetterCapital(text) {
+			return "#/components/schemas/" + text
+		}
+
+		return "#/paths/~1" + text + "/post"
+	}
+
+	base, err := url.Parse(DocsURL)
+	if err != nil {
+		return href
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+// ResolveLinks rewrites the schema and operation links of Markdown whose
+// type or method is not emitted, according to known, to the section of the
+// documentation page: "[object](#/paths/~1object/post)" links a word that
+// happens to match an anchor, and InputFile never becomes a schema.
+func ResolveLinks(markdown string, known func(name string) bool) string {
+	return internalLinkPattern.ReplaceAllStringFunc(markdown, func(link string) string {
+		m := internalLinkPattern.FindStringSubmatch(link)
+
+		name := m[1] + m[2]
+		if known(name) {
+			return link
+		}
+
+		return "](" + DocsURL + "#" + strings.ToLower(name) + ")"
+	})
+}
+
+// listItemMarker returns the Markdown marker of the i-th item of a ul or ol.
+func listItemMarker(list string, i int) string {
+	if list == "ol" {
+		return strconv.Itoa(i+1) + ". "
+	}
+
+	return "- "
+}
+
+func collapseSpace(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s == "" {
+			return ""
+		}
+
+		return " "
+	}
+
+	res := strings.Join(fields, " ")
+
+	if first := s[0]; first == ' ' || first == '\n' || first == '\t' || first == '\r' {
+		res = " " + res
+	}
+
+	if last := s[len(s)-1]; last == ' ' || last == '\n' || last == '\t' || last == '\r' {
+		res += " "
+	}
+
+	return res
+}
@@ -0,0 +1,101 @@
+package parser //nolint:testpackage // tests verify internal helpers
+
+import "testing"
+
+func TestMarkdown(t *testing.T) { //nolint:funlen // table covers each supported element
+	cases := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "formatting",
+			html: `<em>Optional</em>. <strong>True</strong>, if the <code>chat_id</code> is set`,
+			want: "*Optional*. **True**, if the `chat_id` is set",
+		},
+		{
+			name: "type and method links",
+			html: `Sent <a href="#message">Message</a>, see <a href="#sendmessage">sendMessage</a>`,
+			want: "Sent [Message](#/components/schemas/Message), see [sendMessage](#/paths/~1sendMessage/post)",
+		},
+		{
+			name: "other links",
+			html: `See <a href="#formatting-options">formatting options</a>, <a href="/bots/webapps">Web Apps</a> ` +
+				`and <a href="https://telegram.org/blog">the blog</a>`,
+			want: "See [formatting options](https://core.telegram.org/bots/api#formatting-options), " +
+				"[Web Apps](https://core.telegram.org/bots/webapps) and [the blog](https://telegram.org/blog)",
+		},
+		{
+			name: "emoji and line breaks",
+			html: `Dice <img class="emoji" src="dice.png" alt="🎲"><br/>Second  line`,
+			want: "Dice 🎲\nSecond line",
+		},
+		{
+			name: "escaping",
+			html: `Use *bold* and [brackets]`,
+			want: `Use \*bold\* and \[brackets\]`,
+		},
+		{
+			name: "whitespace around emphasis",
+			html: "Pass<em> True </em>to\n\t enable",
+			want: "Pass *True* to enable",
+		},
+		{
+			name: "code block",
+			html: "Example:<pre>  indented\ncode</pre>",
+			want: "Example:\n```\n  indented\ncode\n```",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := mustDoc(t, "<html><body><div>"+tc.html+"</div></body></html>")
+
+			if got := Markdown(doc.Find("div").First()); got != tc.want {
+				t.Fatalf("Markdown() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseTypeMarkdownDescriptions(t *testing.T) {
+	doc := mustDoc(t, `
+		<html><body>
+		<h4><a class="anchor" name="reply"></a>Reply</h4>
+		<p>Describes a reply to a <a href="#message">Message</a>.</p>
+		<ol><li>First</li><li>Second</li></ol>
+		<table><tbody>
+		  <tr><td>chat</td><td>Chat</td><td><em>Optional</em>. Chat, can be either “private” or “group”</td></tr>
+		</tbody></table>
+		</body></html>
+	`)
+
+	typeDef, err := ParseType(doc, "reply")
+	if err != nil {
+		t.Fatalf("ParseType returned error: %v", err)
+	}
+
+	want := []string{"Describes a reply to a [Message](#/components/schemas/Message).", "1. First", "2. Second"}
+	for i, line := range want {
+		if typeDef.Description[i] != line {
+			t.Fatalf("description[%d] = %q, want %q", i, typeDef.Description[i], line)
+		}
+	}
+
+	field := typeDef.Fields[0]
+	if field.Required || len(field.Enum) != 2 || field.Description != "*Optional*. Chat, can be either “private” or “group”" {
+		t.Fatalf("expected heuristics on plain text and Markdown description, got %#v", field)
+	}
+}
+
+func TestResolveLinks(t *testing.T) {
+	in := "A [Message](#/components/schemas/Message) or [InputFile](#/components/schemas/InputFile) " +
+		"from [sendMessage](#/paths/~1sendMessage/post), a JSON-serialized [object](#/paths/~1object/post)"
+	want := "A [Message](#/components/schemas/Message) or [InputFile](https://core.telegram.org/bots/api#inputfile) " +
+		"from [sendMessage](#/paths/~1sendMessage/post), a JSON-serialized [object](https://core.telegram.org/bots/api#object)"
+
+	known := func(name string) bool { return name == "Message" || name == "sendMessage" }
+	if got := ResolveLinks(in, known); got != want {
+		t.Fatalf("ResolveLinks() = %q, want %q", got, want)
+	}
+}
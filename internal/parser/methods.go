@@ -23,8 +23,22 @@ type MethodDef struct {
 	Tags        []string
 	Description []string
 	Notes       []string
-	Params      map[string]MethodParamDef
-	Return      *TypeRef
+	// Params keeps the order of the documentation table, which lists
+	// required identifiers first, then content, then options.
+	Params []MethodParamDef
+	Return *TypeRef
+	Deprecation
+}
+
+// Param returns the parameter with the given name.
+func (m *MethodDef) Param(name string) (MethodParamDef, bool) {
+	for _, p := range m.Params {
+		if p.Name == name {
+			return p, true
+		}
+	}
+
+	return MethodParamDef{}, false
 }
 
 // MethodParamDef describes a single parameter in a Telegram API method table.
@@ -33,6 +47,7 @@ type MethodParamDef struct {
 	TypeRef     *TypeRef
 	Required    bool
 	Description string
+	Annotations
 }
 
 // ParseMethod walks the documentation rooted at the provided anchor and
@@ -42,7 +57,6 @@ type MethodParamDef struct {
 func ParseMethod(doc *goquery.Document, anchor string) (*MethodDef, error) {
 	res := &MethodDef{
 		Anchor: anchor,
-		Params: make(map[string]MethodParamDef),
 	}
 
 	el := doc.Find("h4").FilterFunction(func(i int, s *goquery.Selection) bool {
@@ -50,7 +64,7 @@ func ParseMethod(doc *goquery.Document, anchor string) (*MethodDef, error) {
 	})
 	// header with anchor not found
 	if el.Length() == 0 {
-		return nil, fmt.Errorf("parse method at anchor %s: %w", anchor, ErrElementNotFound)
+		return nil, &ParseError{Anchor: anchor, Err: ErrElementNotFound}
 	}
 
 	res.Name = el.Text()
@@ -63,6 +77,8 @@ func ParseMethod(doc *goquery.Document, anchor string) (*MethodDef, error) {
 	// collecting all description paragraphs (even those that appear after tables).
 	// We skip tables but do not stop at them, since return descriptions might
 	// be placed after the parameters table in some methods.
+	var plain []string
+
 	sib := el.Next()
 	for sib.Length() > 0 {
 		if sib.IsMatcher(goquery.Single("h4")) {
@@ -76,57 +92,64 @@ func ParseMethod(doc *goquery.Document, anchor string) (*MethodDef, error) {
 		}
 
 		if sib.IsMatcher(goquery.Single("p")) {
-			res.Description = append(res.Description, strings.TrimSpace(sib.Text()))
+			plain = append(plain, strings.TrimSpace(sib.Text()))
+			res.Description = append(res.Description, Markdown(sib))
 		}
 
 		sib = sib.Next()
 	}
 
 	// Try to extract a return type from the description paragraphs
-	rt := extractReturnType(res.Description)
+	rt := extractReturnType(plain)
 	if strings.TrimSpace(rt) == "" {
-		return nil, fmt.Errorf("parse method %s (%s): %w", res.Name, anchor, ErrReturnTypeNotParsed)
+		return nil, &ParseError{
+			Anchor: anchor,
+			Name:   res.Name,
+			Text:   strings.Join(plain, " "),
+			Err:    ErrReturnTypeNotParsed,
+		}
 	}
 
 	res.Return = NewTypeRef(rt)
+	res.Deprecation = parseDeprecation(strings.Join(plain, " "))
 
 	// Limit our search to the section between this header and the next h4
 	section := el.NextUntil("h4")
 
 	section.Find("table tbody tr").Each(func(index int, tr *goquery.Selection) {
-		name := ""
 		def := MethodParamDef{}
 
-		var optionalValue string
+		var optionalValue, plain string
 
 		tr.Find("td").Each(func(tdIndex int, td *goquery.Selection) {
 			switch tdIndex {
 			case 0:
-				name = td.Text()
+				def.Name = strings.TrimSpace(td.Text())
 			case paramTypeColumnIndex:
 				def.TypeRef = NewTypeRef(td.Text())
 			case paramOptionalColumnIndex:
 				optionalValue = strings.TrimSpace(td.Text())
 			case paramDescriptionColumnIndex:
-				def.Description = td.Text()
+				plain = td.Text()
+				def.Description = Markdown(td)
 			}
 		})
 
+		if def.Name == "" {
+			return
+		}
+
 		// Determine required based on "Required" column OR description starting with Optional
-		def.Required = !isOptionalDescription(def.Description) && !strings.EqualFold(optionalValue, "Optional")
+		def.Required = !isOptionalDescription(plain) && !strings.EqualFold(optionalValue, "Optional")
 
-		// Force chat_id to be Int64 for method parameters as well
-		if name == "chat_id" || strings.HasSuffix(name, "_chat_id") {
-			def.TypeRef = NewTypeRef("Int64")
-		} else if strings.Contains(strings.ToLower(def.Description), "64-bit integer") {
-			def.TypeRef = NewTypeRef("Int64")
-		}
+		def.Annotations = ParseAnnotations(plain)
+		def.Int64 = isInt64Field(plain)
 
-		res.Params[name] = def
+		res.Params = append(res.Params, def)
 	})
 
 	section.Find("blockquote p").Each(func(index int, p *goquery.Selection) {
-		res.Notes = append(res.Notes, p.Text())
+		res.Notes = append(res.Notes, Markdown(p))
 	})
 
 	return res, nil
@@ -530,7 +553,9 @@ func normalizeReturnTypePhrase(s string) string {
 
 		ls = strings.ToLower(s)
 		for _, suf := range suffixes {
-			if strings.HasSuffix(ls, suf) {
+			// Keep the item of "Array of Messages" or "Array of Stories",
+			// which the suffixes would otherwise strip away.
+			if strings.HasSuffix(ls, suf) && ls != "array"+suf && ls != "array of"+suf {
 				s = strings.TrimSpace(s[:len(s)-len(suf)])
 				trimmed = true
 
@@ -542,6 +567,7 @@ func normalizeReturnTypePhrase(s string) string {
 			break
 		}
 	}
+
 	// Capitalize first letter of common scalar names when found alone
 	switch ls {
 	case "string":
@@ -62,15 +62,24 @@ func TestParseMethodSuccess(t *testing.T) {
 		t.Fatalf("expected return type String, got %#v", method.Return)
 	}
 
-	if got, ok := method.Params["chat_id"]; !ok || got.TypeRef.RawType != "Int64" || got.Required {
+	names := make([]string, 0, len(method.Params))
+	for _, p := range method.Params {
+		names = append(names, p.Name)
+	}
+
+	if !reflect.DeepEqual(names, []string{"chat_id", "limit", "from_chat_id"}) {
+		t.Fatalf("expected documented parameter order, got %v", names)
+	}
+
+	if got, ok := method.Param("chat_id"); !ok || got.TypeRef.RawType != "String" || got.Int64 || got.Required {
 		t.Fatalf("chat_id param not normalized: %#v", got)
 	}
 
-	if got, ok := method.Params["limit"]; !ok || got.TypeRef.RawType != "Integer" || !got.Required {
+	if got, ok := method.Param("limit"); !ok || got.TypeRef.RawType != "Integer" || got.Int64 || !got.Required {
 		t.Fatalf("limit param missing required flag: %#v", got)
 	}
 
-	if got, ok := method.Params["from_chat_id"]; !ok || got.TypeRef.RawType != "Int64" || got.Required {
+	if got, ok := method.Param("from_chat_id"); !ok || got.TypeRef.RawType != "Integer or String" || got.Int64 || got.Required {
 		t.Fatalf("from_chat_id param not normalized: %#v", got)
 	}
 
@@ -109,13 +118,19 @@ func TestParseMethodInt64Params(t *testing.T) {
 		t.Fatalf("ParseMethod returned error: %v", err)
 	}
 
-	if got, ok := method.Params["big_param"]; !ok || got.TypeRef.RawType != "Int64" {
+	if got, ok := method.Param("big_param"); !ok || !got.Int64 {
 		t.Fatalf("big_param param not normalized: %#v", got)
 	}
 
-	if got, ok := method.Params["mixed_param"]; !ok || got.TypeRef.RawType != "Int64" {
+	got, ok := method.Param("mixed_param")
+	if !ok || !got.Int64 {
 		t.Fatalf("mixed_param param not normalized: %#v", got)
 	}
+
+	spec := got.Apply(got.TypeRef.ToTypeSpec())
+	if len(spec.OneOf) != 2 || spec.OneOf[0].Type != "string" || spec.OneOf[1].Format != "int64" {
+		t.Fatalf("expected string or int64 union, got %#v", spec)
+	}
 }
 
 func TestParseMethodErrors(t *testing.T) {
@@ -218,6 +233,18 @@ func TestNormalizeReturnTypePhrase(t *testing.T) { //nolint:cyclop // broad phra
 		t.Fatalf("expected Array of Message, got %q", got)
 	}
 
+	if got := normalizeReturnTypePhrase("an array of Messages that were sent"); got != "Array of Messages" {
+		t.Fatalf("expected Array of Messages, got %q", got)
+	}
+
+	if got := normalizeReturnTypePhrase("an array of Stories"); got != "Array of Stories" {
+		t.Fatalf("expected Array of Stories, got %q", got)
+	}
+
+	if got := normalizeReturnTypePhrase("an array of UserProfilePhotos"); got != "Array of UserProfilePhotos" {
+		t.Fatalf("expected a type name ending in s to be kept, got %q", got)
+	}
+
 	if got := normalizeReturnTypePhrase("chat link as ChatInviteLink"); got != "ChatInviteLink" {
 		t.Fatalf("expected ChatInviteLink via suffix check, got %q", got)
 	}
@@ -442,6 +469,11 @@ func TestExtractReturnType(t *testing.T) { //nolint:funlen // large table ensure
 			paras:    []string{"On success, the stopped Poll is returned."},
 			expected: "Poll",
 		},
+		{
+			name:     "On success array of plural Messages",
+			paras:    []string{"On success, an array of Messages that were sent is returned."},
+			expected: "Array of Messages",
+		},
 		{
 			name:     "On success returns array no comma",
 			paras:    []string{"On success returns Array of Sticker."},
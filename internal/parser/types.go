@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/PuerkitoBio/goquery"
 
@@ -238,6 +239,9 @@ type TypeDef struct {
 	Description []string
 	Notes       []string
 	Fields      []TypeFieldDef
+	// OneOf lists the variants of an abstract type such as ChatMember, which
+	// the docs describe as "one of the following" instead of a fields table.
+	OneOf []string
 }
 
 // TypeFieldDef describes an individual field inside a Telegram object schema.
@@ -246,6 +250,7 @@ type TypeFieldDef struct {
 	TypeRef     *TypeRef
 	Required    bool
 	Description string
+	Annotations
 }
 
 // ParseType parses a Telegram type definition starting at the provided anchor
@@ -261,7 +266,7 @@ func ParseType(doc *goquery.Document, anchor string) (*TypeDef, error) {
 		return s.Children().First().Is(fmt.Sprintf("a.anchor[Name='%s']", anchor))
 	})
 	if header.Length() == 0 {
-		return nil, ErrElementNotFound
+		return nil, &ParseError{Anchor: anchor, Err: ErrElementNotFound}
 	}
 
 	le := header.First()
@@ -273,6 +278,8 @@ func ParseType(doc *goquery.Document, anchor string) (*TypeDef, error) {
 
 	// Limit our search to the section between this header and the next h4
 	section := le.NextUntil("h4")
+	hasFields := section.Filter("table").Length() > 0
+	unionIntro := false
 
 	// Walk siblings preserving order until the first table (fields)
 	for sibling := le.Next(); sibling.Length() > 0; sibling = sibling.Next() {
@@ -289,13 +296,20 @@ func ParseType(doc *goquery.Document, anchor string) (*TypeDef, error) {
 		case "p":
 			text := strings.TrimSpace(sibling.Text())
 			if text != "" {
-				res.Description = append(res.Description, text)
+				res.Description = append(res.Description, Markdown(sibling))
 			}
+
+			unionIntro = !hasFields && isUnionIntro(text)
 		case "ul", "ol":
+			if variants := unionVariants(sibling); unionIntro && len(variants) > 0 {
+				res.OneOf = append(res.OneOf, variants...)
+
+				continue
+			}
+
 			sibling.Find("li").Each(func(i int, li *goquery.Selection) {
-				text := strings.TrimSpace(li.Text())
-				if text != "" {
-					res.Description = append(res.Description, text)
+				if strings.TrimSpace(li.Text()) != "" {
+					res.Description = append(res.Description, listItemMarker(nodeName, i)+Markdown(li))
 				}
 			})
 		}
@@ -305,6 +319,9 @@ func ParseType(doc *goquery.Document, anchor string) (*TypeDef, error) {
 	section.Find("table tbody tr").Each(func(index int, tr *goquery.Selection) {
 		fieldDef := TypeFieldDef{}
 
+		// Heuristics run on the plain text; the Markdown is only for output.
+		var plain string
+
 		tr.Find("td").Each(func(tdIndex int, td *goquery.Selection) {
 			text := strings.TrimSpace(td.Text())
 
@@ -314,31 +331,81 @@ func ParseType(doc *goquery.Document, anchor string) (*TypeDef, error) {
 			case fieldTypeColumnIndex:
 				fieldDef.TypeRef = NewTypeRef(text)
 			case fieldDescriptionColumnIndex:
-				fieldDef.Description = text
+				plain = text
+				fieldDef.Description = Markdown(td)
 			}
 		})
 
 		if fieldDef.Name == "" {
 			return
 		}
-		// Force chat_id to be Int64 regardless of parsed union or other forms
-		if fieldDef.Name == "chat_id" || strings.HasSuffix(fieldDef.Name, "_chat_id") {
-			fieldDef.TypeRef = NewTypeRef("Int64")
-		} else if strings.Contains(strings.ToLower(fieldDef.Description), "64-bit integer") {
-			fieldDef.TypeRef = NewTypeRef("Int64")
-		}
-
-		fieldDef.Required = !isOptionalDescription(fieldDef.Description)
+		fieldDef.Required = !isOptionalDescription(plain)
+		fieldDef.Annotations = ParseAnnotations(plain)
+		fieldDef.Int64 = isInt64Field(plain)
 		res.Fields = append(res.Fields, fieldDef)
 	})
 
 	// Parse notes inside blockquotes in the section
 	section.Find("blockquote p").Each(func(index int, p *goquery.Selection) {
-		text := strings.TrimSpace(p.Text())
-		if text != "" {
-			res.Notes = append(res.Notes, text)
+		if strings.TrimSpace(p.Text()) != "" {
+			res.Notes = append(res.Notes, Markdown(p))
 		}
 	})
 
 	return res, nil
-}
\ No newline at end of file
+}
+
+// isUnionIntro reports whether a paragraph introduces the list of variants of
+// an abstract type, e.g. "It should be one of" or "the following 6 types of
+// chat members are supported:".
+func isUnionIntro(text string) bool {
+	lower := strings.ToLower(text)
+
+	return strings.Contains(lower, "one of") || strings.Contains(lower, "following")
+}
+
+// unionVariants returns the type names of a list whose items are all single
+// capitalized identifiers, or nil for any other list.
+func unionVariants(list *goquery.Selection) []string {
+	items := list.Find("li")
+	if items.Length() == 0 {
+		return nil
+	}
+
+	variants := make([]string, 0, items.Length())
+
+	ok := true
+
+	items.EachWithBreak(func(_ int, li *goquery.Selection) bool {
+		name := strings.TrimSpace(li.Text())
+		if !isTypeName(name) {
+			ok = false
+
+			return false
+		}
+
+		variants = append(variants, name)
+
+		return true
+	})
+
+	if !ok {
+		return nil
+	}
+
+	return variants
+}
+
+func isTypeName(s string) bool {
+	if !isFirstLetterCapital(s) {
+		return false
+	}
+
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -23,6 +23,7 @@ func TestParseType(t *testing.T) {
 		    <tr><td>target_chat_id</td><td>Integer or String</td><td>Optional. Target chat</td></tr>
 		    <tr><td>big_id</td><td>Integer</td><td>Unique identifier. 64-bit integer.</td></tr>
 		    <tr><td>mixed_id</td><td>String or Integer</td><td>Unique identifier. 64-bit integer.</td></tr>
+		    <tr><td>kind</td><td>String</td><td>Kind, one of “a” or “b”</td></tr>
 		  </tbody>
 		</table>
 		<blockquote><p>Note text.</p></blockquote>
@@ -43,31 +44,35 @@ func TestParseType(t *testing.T) {
 		t.Fatalf("expected three description entries, got %d", got)
 	}
 
-	if got := len(typeDef.Fields); got != 5 {
-		t.Fatalf("expected five fields, got %d", got)
+	if got := len(typeDef.Fields); got != 6 {
+		t.Fatalf("expected six fields, got %d", got)
 	}
 
 	fields := typeDef.Fields
-	if fields[0].Name != "chat_id" || fields[0].TypeRef.RawType != "Int64" || fields[0].Required {
+	if fields[0].Name != "chat_id" || fields[0].TypeRef.RawType != "String" || fields[0].Int64 || fields[0].Required {
 		t.Fatalf("chat_id field not normalized: %#v", fields[0])
 	}
 
-	if !fields[1].Required {
-		t.Fatalf("expected second field to be required")
+	if !fields[1].Required || fields[1].Int64 {
+		t.Fatalf("expected second field to be a required plain integer")
 	}
 
-	if fields[2].Name != "target_chat_id" || fields[2].TypeRef.RawType != "Int64" || fields[2].Required {
+	if fields[2].Name != "target_chat_id" || fields[2].TypeRef.RawType != "Integer or String" || fields[2].Int64 || fields[2].Required {
 		t.Fatalf("target_chat_id field not normalized: %#v", fields[2])
 	}
 
-	if fields[3].Name != "big_id" || fields[3].TypeRef.RawType != "Int64" {
+	if fields[3].Name != "big_id" || fields[3].TypeRef.RawType != "Integer" || !fields[3].Int64 {
 		t.Fatalf("big_id field not normalized: %#v", fields[3])
 	}
 
-	if fields[4].Name != "mixed_id" || fields[4].TypeRef.RawType != "Int64" {
+	if fields[4].Name != "mixed_id" || fields[4].TypeRef.RawType != "String or Integer" || !fields[4].Int64 {
 		t.Fatalf("mixed_id field not normalized: %#v", fields[4])
 	}
 
+	if !reflect.DeepEqual(fields[5].Enum, []string{"a", "b"}) {
+		t.Fatalf("expected enum annotation, got %#v", fields[5].Annotations)
+	}
+
 	if !reflect.DeepEqual(typeDef.Notes, []string{"Note text."}) {
 		t.Fatalf("unexpected notes: %#v", typeDef.Notes)
 	}
@@ -102,6 +107,45 @@ func TestParseTypeStopsAtNextHeader(t *testing.T) {
 	}
 }
 
+func TestParseTypeOneOf(t *testing.T) {
+	doc := mustDoc(t, `
+		<html><body>
+		<h4><a class="anchor" name="chatmember"></a>ChatMember</h4>
+		<p>This object contains information about one member of a chat. Currently, the following 2 types of chat members are supported:</p>
+		<ul>
+		  <li><a href="#chatmemberowner">ChatMemberOwner</a></li>
+		  <li><a href="#chatmembermember">ChatMemberMember</a></li>
+		</ul>
+		<h4><a class="anchor" name="notes"></a>Notes</h4>
+		<p>Some of the following rules apply:</p>
+		<ul><li>Bots can't initiate conversations</li></ul>
+		<h4><a class="anchor" name="end"></a>End</h4>
+		</body></html>
+	`)
+
+	typeDef, err := ParseType(doc, "chatmember")
+	if err != nil {
+		t.Fatalf("ParseType returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(typeDef.OneOf, []string{"ChatMemberOwner", "ChatMemberMember"}) {
+		t.Fatalf("unexpected variants: %#v", typeDef.OneOf)
+	}
+
+	if len(typeDef.Description) != 1 {
+		t.Fatalf("expected variants to be dropped from description, got %#v", typeDef.Description)
+	}
+
+	notes, err := ParseType(doc, "notes")
+	if err != nil {
+		t.Fatalf("ParseType returned error: %v", err)
+	}
+
+	if notes.OneOf != nil || len(notes.Description) != 2 {
+		t.Fatalf("expected prose list to stay in description, got %#v", notes)
+	}
+}
+
 //nolint:cyclop,funlen // exhaustive assertions for union parsing
 func TestTypeRefUnionAndSpec(t *testing.T) {
 	if parts := NewTypeRef("Sticker or Photo").UnionParts(); !reflect.DeepEqual(parts, []string{"Sticker", "Photo"}) {
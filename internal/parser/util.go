@@ -26,3 +26,11 @@ func isOptionalDescription(s string) bool {
 
 	return strings.HasPrefix(ls, "optional")
 }
+
+// isInt64Field reports whether the integer parts of a field need the int64
+// format because its description warns that the value does not fit into 32
+// bits ("a signed 64-bit integer ... is safe"). Chat identifiers, which carry
+// no such warning, are marked by the default overrides.
+func isInt64Field(description string) bool {
+	return strings.Contains(strings.ToLower(description), "64-bit")
+}
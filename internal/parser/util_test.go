@@ -18,3 +18,19 @@ func TestIsOptionalDescription(t *testing.T) {
 		}
 	}
 }
+
+func TestIsInt64Field(t *testing.T) {
+	cases := []struct {
+		description string
+		want        bool
+	}{
+		{"Unique identifier for this user or bot. A 64-bit integer is safe for storing it.", true},
+		{"Unique identifier for the target chat or username of the target channel", false},
+		{"Unique message identifier inside this chat", false},
+	}
+	for _, tc := range cases {
+		if got := isInt64Field(tc.description); got != tc.want {
+			t.Fatalf("isInt64Field(%q) = %v, want %v", tc.description, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"github.com/metalagman/tgbotspec/internal/openapi"
+	"github.com/metalagman/tgbotspec/internal/parser"
+)
+
+// addDiscriminators walks spec and attaches an OpenAPI discriminator to every
+// oneOf made only of refs whose types share a required constant field, e.g.
+// "type" for the InputMedia* family or "status" for ChatMember*.
+func addDiscriminators(spec *openapi.TypeSpec, typesMap map[string]parser.TypeDef) *openapi.TypeSpec {
+	if spec == nil {
+		return nil
+	}
+
+	if spec.Items != nil {
+		spec.Items = addDiscriminators(spec.Items, typesMap)
+	}
+
+	for _, list := range [][]openapi.TypeSpec{spec.OneOf, spec.AnyOf, spec.AllOf} {
+		for i := range list {
+			addDiscriminators(&list[i], typesMap)
+		}
+	}
+
+	if spec.Discriminator == nil && len(spec.OneOf) >= parser.MinUnionParts {
+		spec.Discriminator = discriminatorFor(spec.OneOf, typesMap)
+	}
+
+	return spec
+}
+
+// discriminatorFor returns the discriminator of a union of refs, or nil when
+// the members do not share a required constant field with distinct values.
+func discriminatorFor(members []openapi.TypeSpec, typesMap map[string]parser.TypeDef) *openapi.Discriminator {
+	defs := make([]parser.TypeDef, 0, len(members))
+
+	for _, m := range members {
+		if m.Ref == nil {
+			return nil
+		}
+
+		def, ok := typesMap[m.Ref.Name]
+		if !ok {
+			return nil
+		}
+
+		defs = append(defs, def)
+	}
+
+	for _, candidate := range defs[0].Fields {
+		if mapping := constantMapping(candidate.Name, defs); mapping != nil {
+			return &openapi.Discriminator{PropertyName: candidate.Name, Mapping: mapping}
+		}
+	}
+
+	return nil
+}
+
+// constantMapping maps the constant value of the named field to the schema of
+// every type, or returns nil if any type lacks it or two types share a value.
+func constantMapping(name string, defs []parser.TypeDef) map[string]string {
+	mapping := make(map[string]string, len(defs))
+
+	for _, def := range defs {
+		value, ok := constantField(def, name)
+		if !ok {
+			return nil
+		}
+
+		if _, dup := mapping[value]; dup {
+			return nil
+		}
+
+		mapping[value] = "#/components/schemas/" + def.Name
+	}
+
+	return mapping
+}
+
+func constantField(def parser.TypeDef, name string) (string, bool) {
+	for _, f := range def.Fields {
+		if f.Name == name && f.Required {
+			return f.Constant()
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,68 @@
+package scraper //nolint:testpackage // tests exercise unexported helpers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/metalagman/tgbotspec/internal/openapi"
+	"github.com/metalagman/tgbotspec/internal/parser"
+)
+
+func constantType(name, field, value string) parser.TypeDef {
+	return parser.TypeDef{
+		Name: name,
+		Fields: []parser.TypeFieldDef{
+			{Name: "media", Required: true},
+			{Name: field, Required: true, Annotations: parser.Annotations{Enum: []string{value}}},
+		},
+	}
+}
+
+func refs(names ...string) []openapi.TypeSpec {
+	res := make([]openapi.TypeSpec, 0, len(names))
+	for _, n := range names {
+		res = append(res, openapi.TypeSpec{Ref: &openapi.TypeRef{Name: n}})
+	}
+
+	return res
+}
+
+func TestAddDiscriminators(t *testing.T) {
+	typesMap := map[string]parser.TypeDef{
+		"InputMediaPhoto": constantType("InputMediaPhoto", "type", "photo"),
+		"InputMediaVideo": constantType("InputMediaVideo", "type", "video"),
+		"InputMediaClone": constantType("InputMediaClone", "type", "photo"),
+		"Other":           constantType("Other", "kind", "other"),
+	}
+
+	spec := addDiscriminators(&openapi.TypeSpec{
+		Type:  "array",
+		Items: &openapi.TypeSpec{OneOf: refs("InputMediaPhoto", "InputMediaVideo")},
+	}, typesMap)
+
+	want := &openapi.Discriminator{
+		PropertyName: "type",
+		Mapping: map[string]string{
+			"photo": "#/components/schemas/InputMediaPhoto",
+			"video": "#/components/schemas/InputMediaVideo",
+		},
+	}
+	if !reflect.DeepEqual(spec.Items.Discriminator, want) {
+		t.Fatalf("unexpected discriminator: %#v", spec.Items.Discriminator)
+	}
+
+	for name, members := range map[string][]openapi.TypeSpec{
+		"duplicate values": refs("InputMediaPhoto", "InputMediaClone"),
+		"no shared field":  refs("InputMediaPhoto", "Other"),
+		"unknown type":     refs("InputMediaPhoto", "Missing"),
+		"inline member":    append(refs("InputMediaPhoto"), openapi.TypeSpec{Type: "string"}),
+	} {
+		if got := addDiscriminators(&openapi.TypeSpec{OneOf: members}, typesMap); got.Discriminator != nil {
+			t.Fatalf("%s: expected no discriminator, got %#v", name, got.Discriminator)
+		}
+	}
+
+	if addDiscriminators(nil, typesMap) != nil {
+		t.Fatal("expected nil spec to stay nil")
+	}
+}
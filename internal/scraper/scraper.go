@@ -1,6 +1,9 @@
 package scraper
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -10,38 +13,92 @@ import (
 	"github.com/PuerkitoBio/goquery"
 
 	"github.com/metalagman/tgbotspec/internal/fetcher"
+	"github.com/metalagman/tgbotspec/internal/ir"
 	"github.com/metalagman/tgbotspec/internal/openapi"
+	"github.com/metalagman/tgbotspec/internal/overrides"
 	"github.com/metalagman/tgbotspec/internal/parser"
 )
 
 var fetchDocument = fetcher.Document
 
+// ErrStrict is returned in strict mode when parsing produced errors.
+var ErrStrict = errors.New("strict mode: documentation has parse errors")
+
 // Options configures the scraper behavior.
 type Options struct {
+	// Source provides the documentation HTML; nil means fetcher.DefaultSource.
+	Source          fetcher.Source
 	MergeUnionTypes bool
+	// Diagnostics, when set, collects the problems found while parsing.
+	Diagnostics *parser.Report
+	// Strict fails the run instead of rendering when any error diagnostic,
+	// such as an unparsed method or an unresolved type reference, was found.
+	Strict bool
+	// OpenAPIVersion selects the emitted dialect, openapi.OpenAPIVersion30
+	// (the default when empty) or openapi.OpenAPIVersion31.
+	OpenAPIVersion string
+	// Format is the output format, openapi.FormatYAML (the default when
+	// empty) or openapi.FormatJSON.
+	Format string
+	// Template, when set, renders the spec with a Go template instead of the
+	// typed document model: openapi.TemplateBuiltin selects the embedded one,
+	// anything else is the path of a template file.
+	Template string
+	// Validate loads the rendered spec with kin-openapi and fails the run,
+	// without writing anything, when the document is not valid OpenAPI.
+	Validate bool
+	// IR, when set, is read as the intermediate representation of the docs
+	// (see package ir) instead of fetching and parsing the HTML from Source.
+	IR io.Reader
+	// EmitIR writes the intermediate representation instead of the spec.
+	EmitIR bool
+	// Overrides, when set, corrects the parsed documentation after the
+	// bundled default overrides (see package overrides).
+	Overrides *overrides.File
+	// Overlays are applied in order to the rendered spec, before it is
+	// validated and converted to the output format.
+	Overlays []*openapi.Overlay
 }
 
 // Run orchestrates fetching the Telegram Bot API docs, parsing them, and
-// rendering the OpenAPI specification to the provided writer.
-func Run(w io.Writer, opts Options) error { //nolint:cyclop,funlen,gocognit
-	doc, err := fetchDocument()
+// rendering the OpenAPI specification to the provided writer. The parsed docs
+// may instead be read from, or written as, the intermediate representation.
+// Fetching stops when ctx is cancelled.
+func Run(ctx context.Context, w io.Writer, opts Options) error { //nolint:cyclop,funlen,gocognit
+	report := opts.Diagnostics
+	if report == nil && opts.Strict {
+		report = &parser.Report{}
+	}
+
+	parsed, err := parse(ctx, opts, report)
 	if err != nil {
-		return fmt.Errorf("fetch document: %w", err)
+		return err
 	}
 
-	apiVersion := extractBotAPIVersion(doc)
+	if opts.EmitIR {
+		if opts.Strict && report.Errors() > 0 {
+			return fmt.Errorf("%w: %d error(s)", ErrStrict, report.Errors())
+		}
+
+		if err := parsed.WriteJSON(w); err != nil {
+			return fmt.Errorf("write IR: %w", err)
+		}
+
+		return nil
+	}
+
+	apiVersion := parsed.APIVersion
 	if apiVersion == "" {
 		apiVersion = "0.0.0"
 	}
 
-	title := extractAPITitle(doc)
+	title := parsed.Title
 	if title == "" {
 		title = "Telegram Bot API"
 	}
 
-	slog.Info("scraper: detected Telegram Bot API", "title", title, "version", apiVersion)
-
-	typeTargets, methodTargets := splitTargets(parser.ParseNavLists(doc), doc)
+	typeTargets, methodTargets := parsed.TypeDefs(), parsed.MethodDefs()
+	sortTargets(typeTargets, methodTargets)
 
 	// Pass 1: Create a map of all types for lookup during merging
 	typesMap := make(map[string]parser.TypeDef, len(typeTargets))
@@ -50,18 +107,20 @@ func Run(w io.Writer, opts Options) error { //nolint:cyclop,funlen,gocognit
 	}
 
 	renderData := openapi.TemplateData{
-		Title:   title,
-		Version: apiVersion,
+		Title:          title,
+		Version:        apiVersion,
+		OpenAPIVersion: opts.OpenAPIVersion,
+		Changelog:      changelog(parsed.Releases()),
 	}
 
+	since := parser.Introduced(parsed.Releases())
+
 	// Pre-populate valid types for union merging validation
 	validTypes := make(map[string]struct{}, len(typeTargets))
 	for name := range typesMap {
 		validTypes[name] = struct{}{}
 	}
 
-	validTypes["ResponseParameters"] = struct{}{}
-
 	seenTypes := make(map[string]struct{}, len(typeTargets))
 
 	for _, t := range typeTargets {
@@ -79,13 +138,25 @@ func Run(w io.Writer, opts Options) error { //nolint:cyclop,funlen,gocognit
 			Name:        t.Name,
 			Tag:         t.Tag,
 			Description: t.Description,
+			Since:       since[t.Name],
+		}
+
+		if len(t.OneOf) > 0 {
+			union := &openapi.TypeSpec{OneOf: make([]openapi.TypeSpec, 0, len(t.OneOf))}
+			for _, variant := range t.OneOf {
+				union.OneOf = append(union.OneOf, openapi.TypeSpec{Ref: &openapi.TypeRef{Name: variant}})
+			}
+
+			spec.Union = addDiscriminators(union, typesMap)
 		}
 		for _, field := range t.Fields {
-			s := field.TypeRef.ToTypeSpec()
+			s := field.Annotations.Apply(field.TypeRef.ToTypeSpec())
 			if opts.MergeUnionTypes {
 				s = mergeUnionTypes(s, validTypes, typesMap)
 			}
 
+			s = addDiscriminators(s, typesMap)
+
 			spec.Fields = append(spec.Fields, openapi.TypeField{
 				Name:        field.Name,
 				Description: field.Description,
@@ -97,23 +168,6 @@ func Run(w io.Writer, opts Options) error { //nolint:cyclop,funlen,gocognit
 		renderData.Types = append(renderData.Types, spec)
 	}
 
-	if _, ok := seenTypes["ResponseParameters"]; !ok {
-		renderData.Types = append(renderData.Types, openapi.Type{
-			Name: "ResponseParameters",
-			Fields: []openapi.TypeField{
-				{
-					Name:   "migrate_to_chat_id",
-					Schema: &openapi.TypeSpec{Type: "integer"},
-				},
-				{
-					Name:   "retry_after",
-					Schema: &openapi.TypeSpec{Type: "integer"},
-				},
-			},
-		})
-		seenTypes["ResponseParameters"] = struct{}{}
-	}
-
 	sort.Slice(renderData.Types, func(i, j int) bool {
 		return renderData.Types[i].Name < renderData.Types[j].Name
 	})
@@ -124,34 +178,33 @@ func Run(w io.Writer, opts Options) error { //nolint:cyclop,funlen,gocognit
 			Tags:              m.Tags,
 			Description:       m.Description,
 			SupportsMultipart: false,
+			Deprecated:        m.Deprecated,
+			ReplacedBy:        m.ReplacedBy,
+			Since:             since[m.Name],
 		}
 		if m.Return != nil {
 			method.Return = m.Return.ToTypeSpec()
 			if opts.MergeUnionTypes {
 				method.Return = mergeUnionTypes(method.Return, validTypes, typesMap)
 			}
-		}
 
-		paramNames := make([]string, 0, len(m.Params))
-		for name := range m.Params {
-			paramNames = append(paramNames, name)
+			method.Return = addDiscriminators(method.Return, typesMap)
 		}
 
-		sort.Strings(paramNames)
-
-		for _, name := range paramNames {
-			param := m.Params[name]
-
-			s := param.TypeRef.ToTypeSpec()
+		for i, param := range m.Params {
+			s := param.Annotations.Apply(param.TypeRef.ToTypeSpec())
 			if opts.MergeUnionTypes {
 				s = mergeUnionTypes(s, validTypes, typesMap)
 			}
 
+			s = addDiscriminators(s, typesMap).WithDescription(param.Description)
+			s.SetExtension("x-order", i)
+
 			method.Params = append(method.Params, openapi.MethodParam{
-				Name:        name,
+				Name:        param.Name,
 				Description: param.Description,
 				Required:    param.Required,
-				Schema:      s.WithDescription(param.Description),
+				Schema:      s,
 			})
 
 			if requiresMultipart(param.TypeRef) {
@@ -162,13 +215,224 @@ func Run(w io.Writer, opts Options) error { //nolint:cyclop,funlen,gocognit
 		renderData.Methods = append(renderData.Methods, method)
 	}
 
-	if err := openapi.RenderTemplate(w, &renderData); err != nil {
-		return fmt.Errorf("render template: %w", err)
+	for _, issue := range openapi.ResolveRefs(&renderData) {
+		if issue.Resolution == "" {
+			report.Errorf(issue.Location, issue.Name, "unresolved type reference %s", issue.Name)
+
+			continue
+		}
+
+		report.Warnf(issue.Location, issue.Name, "resolved type reference %s to %s", issue.Name, issue.Resolution)
+	}
+
+	if opts.Strict && report.Errors() > 0 {
+		return fmt.Errorf("%w: %d error(s)", ErrStrict, report.Errors())
+	}
+
+	var buf bytes.Buffer
+	if err := render(&buf, &renderData, opts); err != nil {
+		return err
+	}
+
+	if err := applyOverlays(&buf, opts.Overlays, report); err != nil {
+		return err
+	}
+
+	if opts.Validate {
+		if err := openapi.Validate(ctx, buf.Bytes()); err != nil {
+			return fmt.Errorf("validate spec: %w", err)
+		}
+	}
+
+	if opts.Format == openapi.FormatJSON {
+		out, err := openapi.YAMLToJSON(buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("convert spec to json: %w", err)
+		}
+
+		buf.Reset()
+		buf.Write(out)
+	}
+
+	if _, err := buf.WriteTo(w); err != nil {
+		return fmt.Errorf("write spec: %w", err)
 	}
 
 	return nil
 }
 
+// Parse returns the parsed documentation, read from opts.IR or fetched from
+// opts.Source and parsed, with the overrides applied but without rendering it.
+// Parse problems are added to opts.Diagnostics.
+func Parse(ctx context.Context, opts Options) (*ir.Document, error) {
+	return parse(ctx, opts, opts.Diagnostics)
+}
+
+func parse(ctx context.Context, opts Options, report *parser.Report) (*ir.Document, error) {
+	doc, err := load(ctx, opts, report)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides.Default().Apply(doc, report)
+
+	if opts.Overrides != nil {
+		opts.Overrides.Apply(doc, report)
+	}
+
+	resolveLinks(doc)
+
+	return doc, nil
+}
+
+// resolveLinks points the description links to types and methods that are
+// not emitted, such as InputFile, at the documentation page instead.
+func resolveLinks(doc *ir.Document) {
+	known := make(map[string]struct{}, len(doc.Types)+len(doc.Methods))
+
+	for _, t := range doc.Types {
+		if t.Name != "InputFile" {
+			known[t.Name] = struct{}{}
+		}
+	}
+
+	for _, m := range doc.Methods {
+		known[m.Name] = struct{}{}
+	}
+
+	resolve := func(s string) string {
+		return parser.ResolveLinks(s, func(name string) bool {
+			_, ok := known[name]
+
+			return ok
+		})
+	}
+
+	resolveAll := func(list []string) {
+		for i := range list {
+			list[i] = resolve(list[i])
+		}
+	}
+
+	for i := range doc.Types {
+		t := &doc.Types[i]
+		resolveAll(t.Description)
+		resolveAll(t.Notes)
+
+		for j := range t.Fields {
+			t.Fields[j].Description = resolve(t.Fields[j].Description)
+		}
+	}
+
+	for i := range doc.Methods {
+		m := &doc.Methods[i]
+		resolveAll(m.Description)
+		resolveAll(m.Notes)
+
+		for j := range m.Params {
+			m.Params[j].Description = resolve(m.Params[j].Description)
+		}
+	}
+
+	for i := range doc.Changelog {
+		for j := range doc.Changelog[i].Items {
+			item := &doc.Changelog[i].Items[j]
+			item.Text = resolve(item.Text)
+		}
+	}
+}
+
+func load(ctx context.Context, opts Options, report *parser.Report) (*ir.Document, error) {
+	if opts.IR != nil {
+		doc, err := ir.Read(opts.IR)
+		if err != nil {
+			return nil, fmt.Errorf("read IR: %w", err)
+		}
+
+		slog.Info("scraper: loaded IR", "title", doc.Title, "version", doc.APIVersion)
+
+		return doc, nil
+	}
+
+	doc, err := fetchDocument(ctx, opts.Source)
+	if err != nil {
+		return nil, fmt.Errorf("fetch document: %w", err)
+	}
+
+	apiVersion := fetcher.BotAPIVersion(doc)
+	title := extractAPITitle(doc)
+
+	slog.Info("scraper: detected Telegram Bot API", "title", title, "version", apiVersion)
+
+	typeTargets, methodTargets := splitTargets(parser.ParseNavLists(doc), doc, report)
+
+	return ir.New(title, apiVersion, typeTargets, methodTargets, parser.ParseChangelog(doc)), nil
+}
+
+// changelog converts the parsed releases for rendering.
+func changelog(releases []parser.Release) []openapi.Release {
+	res := make([]openapi.Release, 0, len(releases))
+
+	for _, r := range releases {
+		release := openapi.Release{Version: r.Version, Date: r.Date}
+		for _, item := range r.Items {
+			release.Items = append(release.Items, openapi.ChangeItem{Text: item.Text, Refs: item.Refs})
+		}
+
+		res = append(res, release)
+	}
+
+	return res
+}
+
+// render writes the spec as YAML, built as a typed document unless a
+// template is requested.
+func render(w io.Writer, data *openapi.TemplateData, opts Options) error {
+	switch opts.Template {
+	case "":
+		if err := openapi.BuildDocument(data).WriteYAML(w); err != nil {
+			return fmt.Errorf("render document: %w", err)
+		}
+	case openapi.TemplateBuiltin:
+		if err := openapi.RenderTemplate(w, data); err != nil {
+			return fmt.Errorf("render template: %w", err)
+		}
+	default:
+		t, err := openapi.LoadTemplate(opts.Template)
+		if err != nil {
+			return err
+		}
+
+		if err := openapi.ExecuteTemplate(w, t, data); err != nil {
+			return fmt.Errorf("render template %s: %w", opts.Template, err)
+		}
+	}
+
+	return nil
+}
+
+// applyOverlays applies the overlays to the spec in buf and reports the
+// actions whose target matched nothing.
+func applyOverlays(buf *bytes.Buffer, overlays []*openapi.Overlay, report *parser.Report) error {
+	if len(overlays) == 0 {
+		return nil
+	}
+
+	out, issues, err := openapi.ApplyOverlays(buf.Bytes(), overlays)
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		report.Warnf(issue.Target, "", "overlay %s: target %s matches nothing", issue.Overlay, issue.Target)
+	}
+
+	buf.Reset()
+	buf.Write(out)
+
+	return nil
+}
+
 func mergeUnionTypes(
 	spec *openapi.TypeSpec,
 	validTypes map[string]struct{},
@@ -279,7 +543,8 @@ func mergeProperties(refs []openapi.TypeSpec, typesMap map[string]parser.TypeDef
 	for _, ref := range refs {
 		if td, ok := typesMap[ref.Ref.Name]; ok {
 			for _, field := range td.Fields {
-				merged.Properties[field.Name] = *field.TypeRef.ToTypeSpec().WithDescription(field.Description)
+				spec := field.Annotations.Apply(field.TypeRef.ToTypeSpec())
+				merged.Properties[field.Name] = *spec.WithDescription(field.Description)
 			}
 		}
 	}
@@ -309,7 +574,11 @@ func commonPrefix(names []string) string {
 	return prefix
 }
 
-func splitTargets(targets []parser.ParseTarget, doc *goquery.Document) ([]parser.TypeDef, []parser.MethodDef) {
+func splitTargets(
+	targets []parser.ParseTarget,
+	doc *goquery.Document,
+	report *parser.Report,
+) ([]parser.TypeDef, []parser.MethodDef) {
 	if len(targets) == 0 {
 		sections := []string{
 			"getting-updates",
@@ -336,6 +605,7 @@ func splitTargets(targets []parser.ParseTarget, doc *goquery.Document) ([]parser
 			td, err := parser.ParseType(doc, target.Anchor)
 			if err != nil {
 				slog.Error("scraper: parse type failed", "anchor", target.Anchor, "error", err)
+				reportParseError(report, target.Anchor, err)
 
 				continue
 			}
@@ -345,6 +615,7 @@ func splitTargets(targets []parser.ParseTarget, doc *goquery.Document) ([]parser
 			md, err := parser.ParseMethod(doc, target.Anchor)
 			if err != nil {
 				slog.Error("scraper: parse method failed", "anchor", target.Anchor, "error", err)
+				reportParseError(report, target.Anchor, err)
 
 				continue
 			}
@@ -353,11 +624,20 @@ func splitTargets(targets []parser.ParseTarget, doc *goquery.Document) ([]parser
 		}
 	}
 
-	sort.Slice(typeTargets, func(i, j int) bool {
+	parser.ResolveReplacements(typeTargets, methodTargets, report)
+	sortTargets(typeTargets, methodTargets)
+
+	return typeTargets, methodTargets
+}
+
+// sortTargets orders types and methods by name, so the output does not depend
+// on the order of the documentation or of a hand-edited IR.
+func sortTargets(typeTargets []parser.TypeDef, methodTargets []parser.MethodDef) {
+	sort.SliceStable(typeTargets, func(i, j int) bool {
 		return typeTargets[i].Name < typeTargets[j].Name
 	})
 
-	sort.Slice(methodTargets, func(i, j int) bool {
+	sort.SliceStable(methodTargets, func(i, j int) bool {
 		return methodTargets[i].Name < methodTargets[j].Name
 	})
 
@@ -367,21 +647,17 @@ func splitTargets(targets []parser.ParseTarget, doc *goquery.Document) ([]parser
 			sort.Strings(methodTargets[i].Tags)
 		}
 	}
-
-	return typeTargets, methodTargets
 }
 
-func extractBotAPIVersion(doc *goquery.Document) string {
-	sel := doc.Find("p strong").FilterFunction(func(i int, s *goquery.Selection) bool {
-		return strings.HasPrefix(strings.TrimSpace(s.Text()), "Bot API ")
-	}).First()
+func reportParseError(report *parser.Report, anchor string, err error) {
+	var parseErr *parser.ParseError
+	if errors.As(err, &parseErr) {
+		report.Errorf(anchor, parseErr.Text, "%s", err)
 
-	text := strings.TrimSpace(sel.Text())
-	if text == "" {
-		return ""
+		return
 	}
 
-	return strings.TrimSpace(strings.TrimPrefix(text, "Bot API "))
+	report.Errorf(anchor, "", "%s", err)
 }
 
 func extractAPITitle(doc *goquery.Document) string {
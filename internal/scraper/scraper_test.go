@@ -2,12 +2,21 @@ package scraper //nolint:testpackage // tests rely on internal helper hooks
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/PuerkitoBio/goquery"
 
+	"github.com/metalagman/tgbotspec/internal/fetcher"
+	"github.com/metalagman/tgbotspec/internal/ir"
 	"github.com/metalagman/tgbotspec/internal/openapi"
+	"github.com/metalagman/tgbotspec/internal/overrides"
 	"github.com/metalagman/tgbotspec/internal/parser"
 )
 
@@ -22,20 +31,6 @@ func docFromString(t *testing.T, html string) *goquery.Document {
 	return doc
 }
 
-func TestExtractBotAPIVersion(t *testing.T) {
-	t.Parallel()
-
-	doc := docFromString(t, `<html><body><p><strong>Bot API 6.7</strong></p></body></html>`)
-	if version := extractBotAPIVersion(doc); version != "6.7" {
-		t.Fatalf("expected version 6.7, got %q", version)
-	}
-
-	docEmpty := docFromString(t, `<html><body></body></html>`)
-	if version := extractBotAPIVersion(docEmpty); version != "" {
-		t.Fatalf("expected empty version, got %q", version)
-	}
-}
-
 func TestExtractAPITitle(t *testing.T) {
 	t.Parallel()
 
@@ -61,6 +56,7 @@ const mockHTML = `
 <body>
 	<a data-target="#User">User</a>
 	<a data-target="#User">Duplicate</a>
+	<a data-target="#Message">Message</a>
 	<a data-target="#InputFile">InputFile</a>
 	<a data-target="#getMe">getMe</a>
 	<a data-target="#sendPhoto">sendPhoto</a>
@@ -74,6 +70,15 @@ const mockHTML = `
 			<tr><td>id</td><td>Integer</td><td>Unique identifier for this user or bot.</td></tr>
 			<tr><td>is_bot</td><td>Boolean</td><td>True, if this user is a bot</td></tr>
 			<tr><td>first_name</td><td>String</td><td>User's or bot's first name</td></tr>
+			<tr><td>kind</td><td>String</td><td>Optional. Kind of the account, can be either “user” or “bot”</td></tr>
+		</tbody>
+	</table>
+
+	<h4><a class="anchor" name="Message"></a>Message</h4>
+	<p>This object represents a message.</p>
+	<table>
+		<tbody>
+			<tr><td>message_id</td><td>Integer</td><td>Unique message identifier inside this chat</td></tr>
 		</tbody>
 	</table>
 
@@ -91,6 +96,7 @@ const mockHTML = `
 		<tbody>
 			<tr><td>chat_id</td><td>Integer or String</td><td>Unique identifier for the target chat</td></tr>
 			<tr><td>photo</td><td>InputFile or String</td><td>Photo to send.</td></tr>
+			<tr><td>caption</td><td>String</td><td>Optional. Photo caption</td></tr>
 		</tbody>
 	</table>
 </body>
@@ -103,12 +109,12 @@ func TestRunWritesOpenAPISpec(t *testing.T) {
 		fetchDocument = original
 	})
 
-	fetchDocument = func() (*goquery.Document, error) {
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
 		return docFromString(t, mockHTML), nil
 	}
 
 	var buf bytes.Buffer
-	if err := Run(&buf, Options{}); err != nil {
+	if err := Run(t.Context(), &buf, Options{}); err != nil {
 		t.Fatalf("Run returned error: %v", err)
 	}
 
@@ -120,10 +126,318 @@ func TestRunWritesOpenAPISpec(t *testing.T) {
 	assertContains(t, out, "operationId: getMe", "getMe method")
 	assertContains(t, out, "User:", "User type")
 	assertContains(t, out, "ResponseParameters:", "ResponseParameters type")
+	assertContains(t, out, "enum:\n", "enum from description")
+	assertContains(t, out, "- user\n", "enum value")
+	assertContains(t, out, "x-order: 2\n", "parameter order hint")
+	assertContains(t, out, "chat_id:\n                  oneOf:\n                    - type: integer\n"+
+		"                    - type: string\n", "chat_id as integer or username")
+
+	if strings.Index(out, "                photo:") > strings.Index(out, "                caption:") {
+		t.Error("expected parameters in documented order")
+	}
 }
 
-//nolint:funlen // test contains inline HTML mock
-func TestRunMergeUnionTypes(t *testing.T) {
+func TestRunJSONFormat(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, mockHTML), nil
+	}
+
+	var buf bytes.Buffer
+	if err := Run(t.Context(), &buf, Options{Format: openapi.FormatJSON, Validate: true}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("expected JSON output, got %v", err)
+	}
+
+	if doc["openapi"] != "3.0.0" {
+		t.Fatalf("unexpected openapi version %v", doc["openapi"])
+	}
+
+	if err := openapi.Validate(t.Context(), buf.Bytes()); err != nil {
+		t.Fatalf("expected JSON output to validate, got %v", err)
+	}
+}
+
+func TestRunBuiltinTemplate(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, mockHTML), nil
+	}
+
+	var buf bytes.Buffer
+	if err := Run(t.Context(), &buf, Options{Template: openapi.TemplateBuiltin, Validate: true}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	// The template quotes the title, the document encoder does not need to.
+	assertContains(t, buf.String(), "title: \"Mock API\"\n", "template rendering")
+}
+
+func TestRunCustomTemplate(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, mockHTML), nil
+	}
+
+	path := filepath.Join(t.TempDir(), "methods.gotmpl")
+	text := "{{ range .Methods }}{{ .Name }}: {{ len .Params }}\n{{ end }}"
+
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Run(t.Context(), &buf, Options{Template: path}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	assertContains(t, buf.String(), "getMe: 0\n", "custom template")
+
+	err := Run(t.Context(), io.Discard, Options{Template: filepath.Join(t.TempDir(), "missing.gotmpl")})
+	if err == nil {
+		t.Fatal("expected error for a missing template")
+	}
+}
+
+func TestRunIR(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, mockHTML), nil
+	}
+
+	var direct, dump bytes.Buffer
+	if err := Run(t.Context(), &direct, Options{}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if err := Run(t.Context(), &dump, Options{EmitIR: true}); err != nil {
+		t.Fatalf("Run with EmitIR returned error: %v", err)
+	}
+
+	assertContains(t, dump.String(), "\"name\": \"getMe\"", "IR method")
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		t.Fatal("documentation must not be fetched when reading IR")
+
+		return nil, nil
+	}
+
+	var fromIR bytes.Buffer
+	if err := Run(t.Context(), &fromIR, Options{IR: &dump}); err != nil {
+		t.Fatalf("Run from IR returned error: %v", err)
+	}
+
+	if fromIR.String() != direct.String() {
+		t.Errorf("spec rendered from IR differs:\n%s\nwant\n%s", fromIR.String(), direct.String())
+	}
+
+	err := Run(t.Context(), io.Discard, Options{IR: strings.NewReader(`{"version": 99}`)})
+	if !errors.Is(err, ir.ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+}
+
+func TestRunChangelog(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	changes := `<h3><a class="anchor" name="recent-changes"></a>Recent changes</h3>
+	<h4><a class="anchor" name="december-29-2023"></a>December 29, 2023</h4>
+	<p><strong>Bot API 7.0</strong></p>
+	<ul>
+		<li>Added the class <a href="#message">Message</a> and the method <a href="#sendphoto">sendPhoto</a>.</li>
+		<li>Added new <a href="#stickers">Stickers</a> features.</li>
+	</ul>
+	<h3>Available types</h3>`
+	html := strings.Replace(mockHTML, "<h3>Available types</h3>", changes, 1)
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, html), nil
+	}
+
+	var buf bytes.Buffer
+	if err := Run(t.Context(), &buf, Options{Validate: true}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	out := buf.String()
+	assertContains(t, out, "      operationId: sendPhoto\n", "sendPhoto method")
+	assertContains(t, out, "      x-since: \"7.0\"\n", "x-since annotation")
+	assertContains(t, out, "x-changelog:\n  - version: \"7.0\"\n    date: \"2023-12-29\"\n", "x-changelog")
+	assertContains(t, out, "          - '#/paths/~1sendPhoto/post'\n", "changelog link")
+	assertContains(t, out, "      - description: Added new [Stickers](https://core.telegram.org/bots/api#stickers) features.\n",
+		"changelog item linking a section")
+
+	if strings.Contains(out, "schemas/Stickers") {
+		t.Errorf("expected no link to the stickers section:\n%s", out)
+	}
+
+	if strings.Count(out, "x-since:") != 2 {
+		t.Errorf("expected x-since on Message and sendPhoto only:\n%s", out)
+	}
+}
+
+func TestRunOverrides(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, mockHTML), nil
+	}
+
+	fixes, err := overrides.Parse("fixes.yaml", strings.NewReader(`
+methods:
+  - name: getMe
+    return: Message
+fields:
+  - path: User.kind
+    drop: true
+  - path: User.gone
+    required: true
+`))
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	report := &parser.Report{}
+	if err := Run(t.Context(), &buf, Options{Overrides: fixes, Diagnostics: report, EmitIR: true}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	doc, err := ir.Read(&buf)
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+
+	for _, m := range doc.Methods {
+		if m.Name == "getMe" && m.Return != "Message" {
+			t.Errorf("getMe return not overridden: %q", m.Return)
+		}
+	}
+
+	for _, typ := range doc.Types {
+		if typ.Name == "User" && len(typ.Fields) != 3 {
+			t.Errorf("User.kind not dropped: %+v", typ.Fields)
+		}
+	}
+
+	if len(report.Diagnostics) != 1 || report.Diagnostics[0].Anchor != "User.gone" {
+		t.Fatalf("expected the stale override to be reported, got %+v", report.Diagnostics)
+	}
+}
+
+func TestRunOverlays(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, mockHTML), nil
+	}
+
+	overlay, err := openapi.ParseOverlay("custom.yaml", []byte(`overlay: 1.0.0
+info:
+  title: Custom
+  version: 1.0.0
+actions:
+  - target: $.components.schemas.User
+    update:
+      x-go-type: tgbot.User
+  - target: $.paths['/gone']
+    remove: true
+`))
+	if err != nil {
+		t.Fatalf("ParseOverlay returned error: %v", err)
+	}
+
+	for _, version := range []string{openapi.OpenAPIVersion30, openapi.OpenAPIVersion31} {
+		var buf bytes.Buffer
+
+		report := &parser.Report{}
+		opts := Options{
+			Overlays:       []*openapi.Overlay{overlay},
+			Diagnostics:    report,
+			Validate:       true,
+			OpenAPIVersion: version,
+			Format:         openapi.FormatJSON,
+		}
+
+		if err := Run(t.Context(), &buf, opts); err != nil {
+			t.Fatalf("Run %s returned error: %v", version, err)
+		}
+
+		assertContains(t, buf.String(), `"x-go-type": "tgbot.User"`, "overlay extension")
+
+		if len(report.Diagnostics) != 1 || report.Diagnostics[0].Anchor != "$.paths['/gone']" {
+			t.Fatalf("expected the unmatched target to be reported, got %+v", report.Diagnostics)
+		}
+	}
+}
+
+func TestRunResolvesLinks(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	html := strings.Replace(mockHTML, "<p>Use this method to send photos.",
+		`<p>Upload an <a href="#inputfile">InputFile</a> as a JSON-serialized <a href="#object">object</a>, `+
+			`see <a href="#getme">getMe</a>. Use this method to send photos.`, 1)
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, html), nil
+	}
+
+	var buf bytes.Buffer
+	if err := Run(t.Context(), &buf, Options{EmitIR: true}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"[InputFile](https://core.telegram.org/bots/api#inputfile)",
+		"[object](https://core.telegram.org/bots/api#object)",
+		"[getMe](#/paths/~1getMe/post)",
+	} {
+		assertContains(t, out, want, "resolved link")
+	}
+}
+
+func TestRunAbstractUnionType(t *testing.T) {
 	original := fetchDocument
 
 	t.Cleanup(func() {
@@ -132,6 +446,47 @@ func TestRunMergeUnionTypes(t *testing.T) {
 
 	html := `
 <html>
+<body>
+	<a data-target="#ReactionType">ReactionType</a>
+	<a data-target="#ReactionTypeEmoji">ReactionTypeEmoji</a>
+	<a data-target="#ReactionTypePaid">ReactionTypePaid</a>
+
+	<h3>Available types</h3>
+	<h4><a class="anchor" name="ReactionType"></a>ReactionType</h4>
+	<p>This object describes the type of a reaction. Currently, it can be one of</p>
+	<ul><li>ReactionTypeEmoji</li><li>ReactionTypePaid</li></ul>
+
+	<h4><a class="anchor" name="ReactionTypeEmoji"></a>ReactionTypeEmoji</h4>
+	<p>The reaction is based on an emoji.</p>
+	<table><tbody><tr><td>type</td><td>String</td><td>Type of the reaction, always “emoji”</td></tr></tbody></table>
+
+	<h4><a class="anchor" name="ReactionTypePaid"></a>ReactionTypePaid</h4>
+	<p>The reaction is paid.</p>
+	<table><tbody><tr><td>type</td><td>String</td><td>Type of the reaction, always “paid”</td></tr></tbody></table>
+</body>
+</html>`
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, html), nil
+	}
+
+	var buf bytes.Buffer
+	if err := Run(t.Context(), &buf, Options{}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	out := buf.String()
+
+	assertContains(t, out, "    ReactionType:\n      description: This object describes the type of a reaction.",
+		"union type")
+	assertContains(t, out, "Currently, it can be one of\n      oneOf:\n", "oneOf union schema")
+	assertContains(t, out, "        - $ref: '#/components/schemas/ReactionTypeEmoji'\n", "union variant")
+	assertContains(t, out, "        propertyName: type\n", "union discriminator")
+	assertContains(t, out, "          paid: '#/components/schemas/ReactionTypePaid'\n", "discriminator mapping")
+}
+
+const mediaGroupHTML = `
+<html>
 <body>
 	<p><strong>Bot API 7.0</strong></p>
 
@@ -139,6 +494,7 @@ func TestRunMergeUnionTypes(t *testing.T) {
     <a data-target="#InputMedia">InputMedia</a>
     <a data-target="#InputMediaPhoto">InputMediaPhoto</a>
     <a data-target="#InputMediaVideo">InputMediaVideo</a>
+    <a data-target="#Message">Message</a>
     <a data-target="#sendMediaGroup">sendMediaGroup</a>
 
 	<h3>Available types</h3>
@@ -154,6 +510,10 @@ func TestRunMergeUnionTypes(t *testing.T) {
 	<p>Represents a video.</p>
     <table><tbody><tr><td>type</td><td>String</td><td>Type of the result</td></tr></tbody></table>
 
+	<h4><a class="anchor" name="Message"></a>Message</h4>
+	<p>This object represents a message.</p>
+    <table><tbody><tr><td>message_id</td><td>Integer</td><td>Unique message identifier</td></tr></tbody></table>
+
 	<h3>Available methods</h3>
 	<h4><a class="anchor" name="sendMediaGroup"></a>sendMediaGroup</h4>
 	<p>Use this method to send a group of photos, videos, documents or audios as an album.
@@ -167,12 +527,19 @@ func TestRunMergeUnionTypes(t *testing.T) {
 </body>
 </html>`
 
-	fetchDocument = func() (*goquery.Document, error) {
-		return docFromString(t, html), nil
+func TestRunMergeUnionTypes(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, mediaGroupHTML), nil
 	}
 
 	var buf bytes.Buffer
-	if err := Run(&buf, Options{MergeUnionTypes: true}); err != nil {
+	if err := Run(t.Context(), &buf, Options{MergeUnionTypes: true}); err != nil {
 		t.Fatalf("Run returned error: %v", err)
 	}
 
@@ -194,6 +561,64 @@ func TestRunMergeUnionTypes(t *testing.T) {
 	// Let's assume the presence of the merged ref is sufficient proof of the merge logic activation.
 }
 
+func TestRunArrayReturnPhrase(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, mediaGroupHTML), nil
+	}
+
+	report := &parser.Report{}
+
+	var buf bytes.Buffer
+	if err := Run(t.Context(), &buf, Options{Format: openapi.FormatJSON, Diagnostics: report}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	// The plural is left to the reference resolver, which only warns.
+	if report.Errors() != 0 || len(report.Diagnostics) != 1 || report.Diagnostics[0].Text != "Messages" {
+		t.Fatalf("expected only the resolved plural to be reported, got %+v", report.Diagnostics)
+	}
+
+	var doc struct {
+		Paths map[string]struct {
+			Post struct {
+				Responses map[string]struct {
+					Content map[string]struct {
+						Schema struct {
+							AllOf []struct {
+								Properties map[string]struct {
+									Type  string `json:"type"`
+									Items struct {
+										Ref string `json:"$ref"`
+									} `json:"items"`
+								} `json:"properties"`
+							} `json:"allOf"`
+						} `json:"schema"`
+					} `json:"content"`
+				} `json:"responses"`
+			} `json:"post"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+
+	allOf := doc.Paths["/sendMediaGroup"].Post.Responses["200"].Content["application/json"].Schema.AllOf
+	if len(allOf) != 2 {
+		t.Fatalf("expected an OkResponse envelope, got %+v", allOf)
+	}
+
+	result := allOf[1].Properties["result"]
+	if result.Type != "array" || result.Items.Ref != "#/components/schemas/Message" {
+		t.Fatalf("expected an array of Message, got %+v", result)
+	}
+}
+
 func TestRunWithEmptyDoc(t *testing.T) {
 	original := fetchDocument
 
@@ -207,25 +632,167 @@ func TestRunWithEmptyDoc(t *testing.T) {
 			<h4><a class="anchor" name="ResponseParameters"></a>ResponseParameters</h4>
 			<table><tbody><tr><td>retry_after</td><td>Integer</td><td>desc</td></tr></tbody></table>
 		</body></html>`
-	fetchDocument = func() (*goquery.Document, error) {
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
 		return docFromString(t, html), nil
 	}
 
 	var buf bytes.Buffer
-	if err := Run(&buf, Options{}); err != nil {
+	if err := Run(t.Context(), &buf, Options{}); err != nil {
 		t.Fatalf("Run returned error: %v", err)
 	}
 
 	out := buf.String()
-	if !strings.Contains(out, "title: \"Telegram Bot API\"") {
+	if !strings.Contains(out, "title: Telegram Bot API\n") {
 		t.Error("expected default title")
 	}
 
-	if !strings.Contains(out, "version: \"0.0.0\"") {
+	if !strings.Contains(out, "version: 0.0.0\n") {
 		t.Error("expected default version")
 	}
 }
 
+func TestRunStrict(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	html := `
+<html><body>
+	<a data-target="#Reply">Reply</a>
+	<a data-target="#Message">Message</a>
+	<a data-target="#brokenMethod">brokenMethod</a>
+	<h3>Available types</h3>
+	<h4><a class="anchor" name="Reply"></a>Reply</h4>
+	<table><tbody>
+		<tr><td>messages</td><td>Array of Messages</td><td>Replied messages</td></tr>
+		<tr><td>origin</td><td>MessageOrigin</td><td>Origin of the reply</td></tr>
+	</tbody></table>
+	<h4><a class="anchor" name="Message"></a>Message</h4>
+	<table><tbody><tr><td>text</td><td>String</td><td>Text</td></tr></tbody></table>
+	<h3>Available methods</h3>
+	<h4><a class="anchor" name="brokenMethod"></a>brokenMethod</h4>
+	<p>Does something mysterious.</p>
+</body></html>`
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, html), nil
+	}
+
+	report := &parser.Report{}
+
+	var buf bytes.Buffer
+	if err := Run(t.Context(), &buf, Options{Diagnostics: report}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if report.Errors() != 2 || len(report.Diagnostics) != 3 {
+		t.Fatalf("expected parse and reference errors, got %#v", report.Diagnostics)
+	}
+
+	if d := report.Diagnostics[0]; d.Anchor != "brokenMethod" || d.Text != "Does something mysterious." {
+		t.Fatalf("unexpected parse diagnostic %#v", d)
+	}
+
+	if d := report.Diagnostics[1]; d.Severity != parser.SeverityWarning || d.Anchor != "Reply.messages" {
+		t.Fatalf("unexpected fixed reference diagnostic %#v", d)
+	}
+
+	if d := report.Diagnostics[2]; d.Severity != parser.SeverityError || d.Anchor != "Reply.origin" ||
+		d.Text != "MessageOrigin" {
+		t.Fatalf("unexpected reference diagnostic %#v", d)
+	}
+
+	assertContains(t, buf.String(), "$ref: '#/components/schemas/Message'", "fixed plural reference")
+
+	buf.Reset()
+
+	err := Run(t.Context(), &buf, Options{Strict: true})
+	if !errors.Is(err, ErrStrict) {
+		t.Fatalf("expected ErrStrict, got %v", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Fatal("expected no output in strict mode with errors")
+	}
+}
+
+func TestRunValidate(t *testing.T) {
+	original := fetchDocument
+
+	t.Cleanup(func() {
+		fetchDocument = original
+	})
+
+	html := mockHTML
+
+	fetchDocument = func(context.Context, fetcher.Source) (*goquery.Document, error) {
+		return docFromString(t, html), nil
+	}
+
+	var buf bytes.Buffer
+	if err := Run(t.Context(), &buf, Options{Validate: true}); err != nil {
+		t.Fatalf("expected generated spec to be valid, got %v", err)
+	}
+
+	if buf.Len() == 0 {
+		t.Fatal("expected rendered OpenAPI output")
+	}
+
+	buf.Reset()
+
+	if err := Run(t.Context(), &buf, Options{Validate: true, OpenAPIVersion: openapi.OpenAPIVersion31}); err != nil {
+		t.Fatalf("expected OpenAPI 3.1 spec to be valid, got %v", err)
+	}
+
+	assertContains(t, buf.String(), "openapi: 3.1.0\n", "OpenAPI 3.1 header")
+
+	// The written 3.1 spec is validated, including what overlays changed.
+	overlay, err := openapi.ParseOverlay("broken.yaml", []byte(`overlay: 1.0.0
+info: {title: Broken, version: 1.0.0}
+actions:
+  - target: $.components.schemas.User
+    update:
+      type: [strange, "null"]
+`))
+	if err != nil {
+		t.Fatalf("ParseOverlay returned error: %v", err)
+	}
+
+	buf.Reset()
+
+	var validationErr *openapi.ValidationError
+
+	err = Run(t.Context(), &buf, Options{
+		Validate:       true,
+		OpenAPIVersion: openapi.OpenAPIVersion31,
+		Overlays:       []*openapi.Overlay{overlay},
+	})
+	if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), "components.schemas.User") {
+		t.Fatalf("expected the overlaid User schema to be invalid, got %v", err)
+	}
+
+	html = `
+<html><body>
+	<a data-target="#Reply">Reply</a>
+	<h3>Available types</h3>
+	<h4><a class="anchor" name="Reply"></a>Reply</h4>
+	<table><tbody><tr><td>origin</td><td>MessageOrigin</td><td>Origin of the reply</td></tr></tbody></table>
+</body></html>`
+
+	buf.Reset()
+
+	err = Run(t.Context(), &buf, Options{Validate: true})
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error for a dangling reference, got %v", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Fatal("expected no output for an invalid spec")
+	}
+}
+
 func assertContains(t *testing.T, s, substr, name string) {
 	t.Helper()
 
@@ -257,7 +824,7 @@ func TestSplitTargets(t *testing.T) {
 		{Anchor: "MissingMethod", Mode: parser.ParseModeMethod, Name: "MissingMethod"},
 	}
 
-	types, methods := splitTargets(targets, doc)
+	types, methods := splitTargets(targets, doc, nil)
 	if len(types) != 1 || types[0].Name != "User" {
 		t.Errorf("expected 1 type User, got %d", len(types))
 	}
@@ -267,7 +834,7 @@ func TestSplitTargets(t *testing.T) {
 	}
 
 	// Test with automatic targets (empty input)
-	types, methods = splitTargets(nil, doc)
+	types, methods = splitTargets(nil, doc, nil)
 	if len(types) != 1 || types[0].Name != "User" {
 		t.Errorf("expected 1 automatic type User, got %d", len(types))
 	}